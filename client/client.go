@@ -0,0 +1,416 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"time-meter/logic"
+)
+
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+type Client interface {
+	GetSchedule(ctx context.Context) (Schedule, error)
+	GetScheduleBetween(ctx context.Context, from time.Time, to time.Time) (Schedule, error)
+	PostSchedule(ctx context.Context, tasks []logic.Task, option PostOption) (string, error)
+	GetFreeBusy(ctx context.Context, from time.Time, to time.Time) (logic.FreeBusy, error)
+	GetSlots(ctx context.Context, duration time.Duration, from time.Time, to time.Time) ([]logic.Interval, error)
+	PostPlan(ctx context.Context, items []logic.TodoItem, option PlanOption) (logic.Plan, error)
+	PostShift(ctx context.Context, offset time.Duration, option ShiftOption) (Schedule, error)
+	GetTheme(ctx context.Context) (logic.Selection, string, error)
+	PostTheme(ctx context.Context, name string, ifMatch string) (string, error)
+	GetProfile(ctx context.Context) (logic.Selection, string, error)
+	PostProfile(ctx context.Context, name string, ifMatch string) (string, error)
+	GetSchema(ctx context.Context, name string) (json.RawMessage, error)
+	GetOpenApi(ctx context.Context) (json.RawMessage, error)
+}
+
+type Schedule struct {
+	Tasks []logic.Task
+	ETag  string
+}
+
+type PostOption struct {
+	Mode    string
+	Key     string
+	From    time.Time
+	To      time.Time
+	IfMatch string
+}
+
+type ShiftOption struct {
+	Mode     string
+	From     time.Time
+	Compress bool
+	IfMatch  string
+}
+
+type PlanOption struct {
+	From    time.Time
+	To      time.Time
+	Apply   bool
+	IfMatch string
+}
+
+type PreconditionError struct {
+	ETag    string
+	Current json.RawMessage
+}
+
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+type client struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+func New(baseUrl string) Client {
+	return NewWithHttpClient(baseUrl, http.DefaultClient)
+}
+
+func NewWithHttpClient(baseUrl string, httpClient *http.Client) Client {
+	ret := new(client)
+	ret.baseUrl = strings.TrimSuffix(baseUrl, "/")
+	ret.httpClient = httpClient
+	return ret
+}
+
+func (se *StatusError) Error() string {
+	if se.Message == "" {
+		return fmt.Sprintf("unexpected status %d", se.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status %d: %s", se.StatusCode, se.Message)
+}
+
+func (e *PreconditionError) Error() string {
+	return ErrPreconditionFailed.Error()
+}
+
+func (e *PreconditionError) Unwrap() error {
+	return ErrPreconditionFailed
+}
+
+func (e *PreconditionError) Tasks() ([]logic.Task, error) {
+	var ret []logic.Task
+	err := json.Unmarshal(e.Current, &ret)
+	return ret, err
+}
+
+func (e *PreconditionError) Selection() (logic.Selection, error) {
+	var ret logic.Selection
+	err := json.Unmarshal(e.Current, &ret)
+	return ret, err
+}
+
+func (c *client) GetSchedule(ctx context.Context) (Schedule, error) {
+	return c.getSchedule(ctx, nil)
+}
+
+func (c *client) GetScheduleBetween(ctx context.Context, from time.Time, to time.Time) (Schedule, error) {
+	query := url.Values{}
+	query.Set("from", from.Format(time.RFC3339))
+	query.Set("to", to.Format(time.RFC3339))
+
+	return c.getSchedule(ctx, query)
+}
+
+func (c *client) getSchedule(ctx context.Context, query url.Values) (Schedule, error) {
+	var ret Schedule
+
+	response, err := c.do(ctx, http.MethodGet, "/schedule", query, nil, nil)
+	if err != nil {
+		return ret, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&ret.Tasks); err != nil {
+		return ret, err
+	}
+
+	ret.ETag = response.Header.Get("ETag")
+
+	return ret, nil
+}
+
+func (c *client) PostSchedule(ctx context.Context, tasks []logic.Task, option PostOption) (string, error) {
+	query := url.Values{}
+	header := http.Header{}
+
+	if option.Mode != "" {
+		query.Set("mode", option.Mode)
+	}
+
+	if option.Key != "" {
+		query.Set("key", option.Key)
+	}
+
+	if !option.From.IsZero() {
+		query.Set("from", option.From.Format(time.RFC3339))
+	}
+
+	if !option.To.IsZero() {
+		query.Set("to", option.To.Format(time.RFC3339))
+	}
+
+	if option.IfMatch != "" {
+		header.Set("If-Match", option.IfMatch)
+	}
+
+	if tasks == nil {
+		tasks = []logic.Task{}
+	}
+
+	response, err := c.do(ctx, http.MethodPost, "/schedule", query, header, tasks)
+	if err != nil {
+		return etagOf(err), err
+	}
+	defer response.Body.Close()
+
+	return response.Header.Get("ETag"), nil
+}
+
+func (c *client) PostShift(ctx context.Context, offset time.Duration, option ShiftOption) (Schedule, error) {
+	var ret Schedule
+
+	query := url.Values{}
+	query.Set("offset", offset.String())
+
+	if option.Mode != "" {
+		query.Set("mode", option.Mode)
+	}
+
+	if !option.From.IsZero() {
+		query.Set("from", option.From.Format(time.RFC3339))
+	}
+
+	if option.Compress {
+		query.Set("compress", "true")
+	}
+
+	response, err := c.do(ctx, http.MethodPost, "/shift", query, ifMatchHeader(option.IfMatch), nil)
+	if err != nil {
+		return ret, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&ret.Tasks); err != nil {
+		return ret, err
+	}
+
+	ret.ETag = response.Header.Get("ETag")
+
+	return ret, nil
+}
+
+func (c *client) GetFreeBusy(ctx context.Context, from time.Time, to time.Time) (logic.FreeBusy, error) {
+	var ret logic.FreeBusy
+
+	err := c.getJson(ctx, "/freebusy", rangeQueryOf(from, to), &ret)
+
+	return ret, err
+}
+
+func (c *client) GetSlots(ctx context.Context, duration time.Duration, from time.Time, to time.Time) ([]logic.Interval, error) {
+	var ret []logic.Interval
+
+	query := rangeQueryOf(from, to)
+	query.Set("duration", duration.String())
+
+	err := c.getJson(ctx, "/slots", query, &ret)
+
+	return ret, err
+}
+
+func (c *client) PostPlan(ctx context.Context, items []logic.TodoItem, option PlanOption) (logic.Plan, error) {
+	var ret logic.Plan
+
+	query := rangeQueryOf(option.From, option.To)
+	if option.Apply {
+		query.Set("apply", "true")
+	}
+
+	if items == nil {
+		items = []logic.TodoItem{}
+	}
+
+	response, err := c.do(ctx, http.MethodPost, "/plan", query, ifMatchHeader(option.IfMatch), items)
+	if err != nil {
+		return ret, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&ret); err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+func (c *client) GetTheme(ctx context.Context) (logic.Selection, string, error) {
+	return c.getSelection(ctx, "/theme")
+}
+
+func (c *client) PostTheme(ctx context.Context, name string, ifMatch string) (string, error) {
+	return c.postSelection(ctx, "/theme", name, ifMatch)
+}
+
+func (c *client) GetProfile(ctx context.Context) (logic.Selection, string, error) {
+	return c.getSelection(ctx, "/profile")
+}
+
+func (c *client) PostProfile(ctx context.Context, name string, ifMatch string) (string, error) {
+	return c.postSelection(ctx, "/profile", name, ifMatch)
+}
+
+func (c *client) getSelection(ctx context.Context, path string) (logic.Selection, string, error) {
+	var ret logic.Selection
+
+	response, err := c.do(ctx, http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		return ret, "", err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&ret); err != nil {
+		return ret, "", err
+	}
+
+	return ret, response.Header.Get("ETag"), nil
+}
+
+func (c *client) getJson(ctx context.Context, path string, query url.Values, value any) error {
+	response, err := c.do(ctx, http.MethodGet, path, query, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return json.NewDecoder(response.Body).Decode(value)
+}
+
+func (c *client) postSelection(ctx context.Context, path string, name string, ifMatch string) (string, error) {
+	body := logic.SelectionRequest{Name: name}
+
+	response, err := c.do(ctx, http.MethodPost, path, nil, ifMatchHeader(ifMatch), body)
+	if err != nil {
+		return etagOf(err), err
+	}
+	defer response.Body.Close()
+
+	return response.Header.Get("ETag"), nil
+}
+
+func (c *client) GetSchema(ctx context.Context, name string) (json.RawMessage, error) {
+	return c.getRaw(ctx, "/schema/"+url.PathEscape(name)+".json")
+}
+
+func (c *client) GetOpenApi(ctx context.Context) (json.RawMessage, error) {
+	return c.getRaw(ctx, "/openapi.json")
+}
+
+func (c *client) getRaw(ctx context.Context, path string) (json.RawMessage, error) {
+	response, err := c.do(ctx, http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return io.ReadAll(response.Body)
+}
+
+func ifMatchHeader(ifMatch string) http.Header {
+	if ifMatch == "" {
+		return nil
+	}
+
+	return http.Header{"If-Match": []string{ifMatch}}
+}
+
+func etagOf(err error) string {
+	var preconditionErr *PreconditionError
+	if errors.As(err, &preconditionErr) {
+		return preconditionErr.ETag
+	}
+
+	return ""
+}
+
+func rangeQueryOf(from time.Time, to time.Time) url.Values {
+	ret := url.Values{}
+
+	if !from.IsZero() {
+		ret.Set("from", from.Format(time.RFC3339))
+	}
+
+	if !to.IsZero() {
+		ret.Set("to", to.Format(time.RFC3339))
+	}
+
+	return ret
+}
+
+func (c *client) do(ctx context.Context, method string, path string, query url.Values, header http.Header, body any) (*http.Response, error) {
+	var bodyReader io.Reader
+
+	if body != nil {
+		if jsonBytes, err := json.Marshal(body); err != nil {
+			return nil, err
+
+		} else {
+			bodyReader = bytes.NewReader(jsonBytes)
+		}
+	}
+
+	requestUrl := c.baseUrl + path
+	if 0 < len(query) {
+		requestUrl += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestUrl, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusPreconditionFailed {
+		defer response.Body.Close()
+
+		current, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, &PreconditionError{ETag: response.Header.Get("ETag"), Current: current}
+	}
+
+	if response.StatusCode < 200 || 300 <= response.StatusCode {
+		message, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		return nil, &StatusError{response.StatusCode, strings.TrimSpace(string(message))}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"time-meter/logic"
+	"time-meter/store"
+	"time-meter/webapi"
+)
+
+func TestPreconditionFailedReturnsCurrentVersion(t *testing.T) {
+	beginAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []logic.Task{{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}}
+
+	wa := webapi.New()
+	wa.SetStore(store.NewMemory(tasks))
+	wa.SetThemes(logic.Selection{Available: []string{"dark"}})
+
+	server := httptest.NewServer(wa)
+	defer server.Close()
+
+	c := New(server.URL)
+	ctx := context.Background()
+
+	schedule, err := c.GetSchedule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etag, err := c.PostSchedule(ctx, nil, PostOption{Mode: "append", IfMatch: `"stale"`})
+
+	var preconditionErr *PreconditionError
+	if !errors.Is(err, ErrPreconditionFailed) || !errors.As(err, &preconditionErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if etag != schedule.ETag || preconditionErr.ETag != schedule.ETag {
+		t.Errorf("returned ETag %s, expected %s", etag, schedule.ETag)
+	}
+
+	if current, err := preconditionErr.Tasks(); err != nil {
+		t.Fatal(err)
+
+	} else if len(current) != 1 || current[0].ID != "a" {
+		t.Errorf("returned tasks %v", current)
+	}
+
+	if _, err := c.PostShift(ctx, time.Minute, ShiftOption{From: beginAt, IfMatch: `"stale"`}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("unexpected shift error %v", err)
+	}
+
+	_, themeETag, err := c.GetTheme(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.PostTheme(ctx, "dark", `"stale"`); !errors.As(err, &preconditionErr) {
+		t.Errorf("unexpected theme error %v", err)
+
+	} else if selection, err := preconditionErr.Selection(); err != nil || selection.Active != "" {
+		t.Errorf("returned selection %v, %v", selection, err)
+	}
+
+	if _, err := c.PostTheme(ctx, "dark", themeETag); err != nil {
+		t.Errorf("unexpected theme error %v", err)
+	}
+}
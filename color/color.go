@@ -0,0 +1,287 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
+func RGB(r, g, b uint8) Color {
+	return Color{r, g, b, 255}
+}
+
+func Parse(str string) (Color, error) {
+	normalized := strings.ToLower(strings.TrimSpace(str))
+
+	if strings.HasPrefix(normalized, "#") {
+		if ret, ok := parseHex(normalized[1:]); ok {
+			return ret, nil
+		}
+
+	} else if name, args, ok := splitFunction(normalized); ok {
+		switch name {
+		case "rgb", "rgba":
+			if ret, ok := parseRgbArgs(args); ok {
+				return ret, nil
+			}
+
+		case "hsl", "hsla":
+			if ret, ok := parseHslArgs(args); ok {
+				return ret, nil
+			}
+		}
+
+	} else if ret, ok := namedColors[normalized]; ok {
+		return ret, nil
+	}
+
+	return Color{}, fmt.Errorf(`invalid color "%s"`, str)
+}
+
+func (c Color) String() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func parseHex(hex string) (Color, bool) {
+	var digits []uint8
+
+	for _, r := range hex {
+		if value, err := strconv.ParseUint(string(r), 16, 8); err != nil {
+			return Color{}, false
+
+		} else {
+			digits = append(digits, uint8(value))
+		}
+	}
+
+	switch len(digits) {
+	case 3:
+		return Color{digits[0] * 17, digits[1] * 17, digits[2] * 17, 255}, true
+
+	case 4:
+		return Color{digits[0] * 17, digits[1] * 17, digits[2] * 17, digits[3] * 17}, true
+
+	case 6:
+		return Color{digits[0]<<4 | digits[1], digits[2]<<4 | digits[3], digits[4]<<4 | digits[5], 255}, true
+
+	case 8:
+		return Color{digits[0]<<4 | digits[1], digits[2]<<4 | digits[3], digits[4]<<4 | digits[5], digits[6]<<4 | digits[7]}, true
+
+	default:
+		return Color{}, false
+	}
+}
+
+func splitFunction(str string) (string, []string, bool) {
+	open := strings.Index(str, "(")
+	if open < 0 || !strings.HasSuffix(str, ")") {
+		return "", nil, false
+	}
+
+	name := strings.TrimSpace(str[:open])
+	body := str[open+1 : len(str)-1]
+
+	var args []string
+	var alpha string
+
+	if slash := strings.Index(body, "/"); 0 <= slash {
+		alpha = strings.TrimSpace(body[slash+1:])
+		body = body[:slash]
+
+		if alpha == "" {
+			return "", nil, false
+		}
+	}
+
+	if strings.Contains(body, ",") {
+		for _, arg := range strings.Split(body, ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+
+	} else {
+		args = strings.Fields(body)
+	}
+
+	if alpha != "" {
+		args = append(args, alpha)
+	}
+
+	return name, args, true
+}
+
+func parseRgbArgs(args []string) (Color, bool) {
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, false
+	}
+
+	var ret Color
+	var ok bool
+
+	for index, target := range []*uint8{&ret.R, &ret.G, &ret.B} {
+		if *target, ok = parseChannel(args[index]); !ok {
+			return Color{}, false
+		}
+	}
+
+	if ret.A, ok = parseAlpha(args, 3); !ok {
+		return Color{}, false
+	}
+
+	return ret, true
+}
+
+func parseHslArgs(args []string) (Color, bool) {
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, false
+	}
+
+	hue, ok := parseHue(args[0])
+	if !ok {
+		return Color{}, false
+	}
+
+	saturation, ok := parsePercent(args[1])
+	if !ok {
+		return Color{}, false
+	}
+
+	lightness, ok := parsePercent(args[2])
+	if !ok {
+		return Color{}, false
+	}
+
+	alpha, ok := parseAlpha(args, 3)
+	if !ok {
+		return Color{}, false
+	}
+
+	r, g, b := hslToRgb(hue, saturation, lightness)
+	return Color{r, g, b, alpha}, true
+}
+
+func parseChannel(arg string) (uint8, bool) {
+	if strings.HasSuffix(arg, "%") {
+		if ratio, ok := parsePercent(arg); ok {
+			return toByte(ratio * 255), true
+		}
+
+		return 0, false
+	}
+
+	if value, err := strconv.ParseFloat(arg, 64); err != nil || value < 0 || 255 < value {
+		return 0, false
+
+	} else {
+		return toByte(value), true
+	}
+}
+
+func parseAlpha(args []string, index int) (uint8, bool) {
+	if len(args) <= index {
+		return 255, true
+	}
+
+	arg := args[index]
+	if strings.HasSuffix(arg, "%") {
+		if ratio, ok := parsePercent(arg); ok {
+			return toByte(ratio * 255), true
+		}
+
+		return 0, false
+	}
+
+	if value, err := strconv.ParseFloat(arg, 64); err != nil || value < 0 || 1 < value {
+		return 0, false
+
+	} else {
+		return toByte(value * 255), true
+	}
+}
+
+func parsePercent(arg string) (float64, bool) {
+	if !strings.HasSuffix(arg, "%") {
+		return 0, false
+	}
+
+	if value, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64); err != nil || value < 0 || 100 < value {
+		return 0, false
+
+	} else {
+		return value / 100, true
+	}
+}
+
+func parseHue(arg string) (float64, bool) {
+	unit := 1.0
+
+	switch {
+	case strings.HasSuffix(arg, "deg"):
+		arg = strings.TrimSuffix(arg, "deg")
+
+	case strings.HasSuffix(arg, "turn"):
+		arg = strings.TrimSuffix(arg, "turn")
+		unit = 360
+
+	case strings.HasSuffix(arg, "rad"):
+		arg = strings.TrimSuffix(arg, "rad")
+		unit = 180 / math.Pi
+	}
+
+	if value, err := strconv.ParseFloat(arg, 64); err != nil {
+		return 0, false
+
+	} else {
+		value = math.Mod(value*unit, 360)
+		if value < 0 {
+			value += 360
+		}
+
+		return value, true
+	}
+}
+
+func hslToRgb(hue, saturation, lightness float64) (uint8, uint8, uint8) {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - chroma/2
+
+	var r, g, b float64
+
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+
+	case hue < 120:
+		r, g, b = x, chroma, 0
+
+	case hue < 180:
+		r, g, b = 0, chroma, x
+
+	case hue < 240:
+		r, g, b = 0, x, chroma
+
+	case hue < 300:
+		r, g, b = x, 0, chroma
+
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return toByte((r + m) * 255), toByte((g + m) * 255), toByte((b + m) * 255)
+}
+
+func toByte(value float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(value))))
+}
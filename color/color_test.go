@@ -0,0 +1,83 @@
+package color
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Color
+	}{
+		{"#f80", Color{255, 136, 0, 255}},
+		{"#F80", Color{255, 136, 0, 255}},
+		{"#f808", Color{255, 136, 0, 136}},
+		{"#ff8800", Color{255, 136, 0, 255}},
+		{"#ff880080", Color{255, 136, 0, 128}},
+		{"  #FF8800  ", Color{255, 136, 0, 255}},
+		{"rgb(255, 136, 0)", Color{255, 136, 0, 255}},
+		{"rgb(255 136 0)", Color{255, 136, 0, 255}},
+		{"rgb(100%, 50%, 0%)", Color{255, 128, 0, 255}},
+		{"rgba(255, 136, 0, 0.5)", Color{255, 136, 0, 128}},
+		{"rgba(255, 136, 0, 25%)", Color{255, 136, 0, 64}},
+		{"rgb(255 136 0 / 0.5)", Color{255, 136, 0, 128}},
+		{"rgb(100% 50% 0% / 50%)", Color{255, 128, 0, 128}},
+		{"RGB(255, 136, 0)", Color{255, 136, 0, 255}},
+		{"hsl(0, 100%, 50%)", Color{255, 0, 0, 255}},
+		{"hsl(120deg 100% 25%)", Color{0, 128, 0, 255}},
+		{"hsl(0.5turn, 100%, 50%)", Color{0, 255, 255, 255}},
+		{"hsl(-120, 100%, 50%)", Color{0, 0, 255, 255}},
+		{"hsl(240 100% 50% / 0.5)", Color{0, 0, 255, 128}},
+		{"hsla(60, 100%, 50%, 0.25)", Color{255, 255, 0, 64}},
+		{"hsl(0, 0%, 100%)", Color{255, 255, 255, 255}},
+		{"red", Color{255, 0, 0, 255}},
+		{"RebeccaPurple", Color{102, 51, 153, 255}},
+		{"transparent", Color{0, 0, 0, 0}},
+	}
+
+	for _, c := range cases {
+		if actual, err := Parse(c.input); err != nil {
+			t.Errorf("%q: %v", c.input, err)
+
+		} else if actual != c.expected {
+			t.Errorf("%q: parsed %v, expected %v", c.input, actual, c.expected)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"#",
+		"#ff",
+		"#fffff",
+		"#fffffffff",
+		"#ggg",
+		"#+12",
+		"rgb(255, 136)",
+		"rgb(255, 136, 0, 0.5, 1)",
+		"rgb(256, 0, 0)",
+		"rgb(-1, 0, 0)",
+		"rgb(101%, 0%, 0%)",
+		"rgba(0, 0, 0, 1.5)",
+		"rgb(0 0 0 /)",
+		"rgb(0, 0, 0",
+		"rgb(a, b, c)",
+		"hsl(0, 100, 50%)",
+		"hsl(red, 100%, 50%)",
+		"cmyk(0, 0, 0, 0)",
+		"notacolor",
+	} {
+		if actual, err := Parse(input); err == nil {
+			t.Errorf("%q: parsed %v", input, actual)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, c := range []Color{{255, 136, 0, 255}, {1, 2, 3, 4}, {0, 0, 0, 0}} {
+		if parsed, err := Parse(c.String()); err != nil || parsed != c {
+			t.Errorf("%v: formatted %s and parsed %v, %v", c, c.String(), parsed, err)
+		}
+	}
+}
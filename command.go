@@ -0,0 +1,421 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"time-meter/logic"
+	"time-meter/setting"
+	"time-meter/store"
+	"time-meter/webapi"
+)
+
+const SETTINGS_DIRNAME = "time-meter"
+const SETTINGS_ENV_PREFIX = "TIME_METER_"
+
+type settingsLocation struct {
+	name string
+	dir  string
+}
+
+func settingsLocations() []settingsLocation {
+	ret := []settingsLocation{}
+
+	if programData := os.Getenv("ProgramData"); programData != "" {
+		ret = append(ret, settingsLocation{"system", filepath.Join(programData, SETTINGS_DIRNAME)})
+	}
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		ret = append(ret, settingsLocation{"user", filepath.Join(configDir, SETTINGS_DIRNAME)})
+	}
+
+	ret = append(ret, settingsLocation{"workspace", "."})
+
+	return ret
+}
+
+func defaultSettingsSources() []setting.Source {
+	ret := []setting.Source{}
+
+	for _, location := range settingsLocations() {
+		ret = append(ret, setting.FileSource(location.name, filepath.Join(location.dir, SETTINGS_FILENAME)))
+	}
+
+	ret = append(ret, setting.EnvSource(SETTINGS_ENV_PREFIX, os.Environ()))
+
+	return ret
+}
+
+func settingsPatterns() []string {
+	ret := []string{}
+
+	for _, location := range settingsLocations() {
+		ret = append(ret, filepath.Join(location.dir, SETTINGS_FILENAME))
+		ret = append(ret, filepath.Join(location.dir, "text.*.json"))
+	}
+
+	return ret
+}
+
+func runCommand(args []string) error {
+	switch {
+	case matchCommand(args, "config", "explain"):
+		return explainConfig(os.Stdout)
+
+	case matchCommand(args, "freebusy"):
+		return printFreeBusy(os.Stdout, args[1:])
+
+	case matchCommand(args, "slots"):
+		return printSlots(os.Stdout, args[1:])
+
+	case matchCommand(args, "plan"):
+		return printPlan(os.Stdout, args[1:])
+
+	case matchCommand(args, "shift"):
+		return shiftSchedule(os.Stdout, args[1:])
+
+	default:
+		return fmt.Errorf(`unknown command "%s"`, strings.Join(args, " "))
+	}
+}
+
+func matchCommand(args []string, words ...string) bool {
+	if len(args) < len(words) {
+		return false
+	}
+
+	for index, word := range words {
+		if args[index] != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+func explainConfig(w io.Writer) error {
+	origins, err := setting.ExplainLayers(settingsSources...)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+
+	for _, origin := range origins {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", origin.Key, origin.Value, origin.Source)
+	}
+
+	return writer.Flush()
+}
+
+func openCommandStore() (setting.Settings, store.TaskStore, error) {
+	var s setting.Settings
+	s.Default()
+
+	if err := s.LoadLayers(settingsSources...); err != nil {
+		return s, nil, err
+	}
+
+	taskStore, err := newTaskStore(s)
+	if err != nil {
+		return s, nil, err
+	}
+
+	return s, taskStore, nil
+}
+
+func loadCommandTasks() (setting.Settings, []logic.Task, error) {
+	s, taskStore, err := openCommandStore()
+	if err != nil {
+		return s, nil, err
+	}
+	defer taskStore.Close()
+
+	tasks, err := taskStore.Load()
+	if err != nil {
+		return s, nil, err
+	}
+
+	return s, tasks, nil
+}
+
+func printFreeBusy(w io.Writer, args []string) error {
+	if 2 < len(args) {
+		return errors.New("usage: freebusy [from [to]]")
+	}
+
+	now := time.Now()
+	beginAt, endAt, err := parseCommandRange(args, startOfDay(now), now)
+	if err != nil {
+		return err
+	}
+
+	s, tasks, err := loadCommandTasks()
+	if err != nil {
+		return err
+	}
+
+	freeBusy := logic.ComputeFreeBusy(tasks, beginAt, endAt, freeBusyOptionOf(s))
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATE\tBEGIN\tEND\tDURATION")
+
+	for _, interval := range freeBusy.Busy {
+		fmt.Fprintf(writer, "busy\t%s\t%s\t%s\n", formatCommandTime(interval.BeginAt), formatCommandTime(interval.EndAt), interval.Duration())
+	}
+
+	for _, interval := range freeBusy.Free {
+		fmt.Fprintf(writer, "free\t%s\t%s\t%s\n", formatCommandTime(interval.BeginAt), formatCommandTime(interval.EndAt), interval.Duration())
+	}
+
+	return writer.Flush()
+}
+
+func printSlots(w io.Writer, args []string) error {
+	if len(args) < 1 || 3 < len(args) {
+		return errors.New("usage: slots <duration> [from [to]]")
+	}
+
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		return fmt.Errorf(`invalid duration "%s", expected such as "30m"`, args[0])
+	}
+
+	now := time.Now()
+	beginAt, endAt, err := parseCommandRange(args[1:], now, now)
+	if err != nil {
+		return err
+	}
+
+	s, tasks, err := loadCommandTasks()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "BEGIN\tEND\tDURATION")
+
+	for _, slot := range logic.FindSlots(tasks, beginAt, endAt, duration, freeBusyOptionOf(s)) {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", formatCommandTime(slot.BeginAt), formatCommandTime(slot.EndAt), slot.Duration())
+	}
+
+	return writer.Flush()
+}
+
+func printPlan(w io.Writer, args []string) error {
+	apply := false
+	rest := []string{}
+
+	for _, arg := range args {
+		if arg == "--apply" {
+			apply = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) < 1 || 3 < len(rest) {
+		return errors.New("usage: plan <todo-file> [from [to]] [--apply]")
+	}
+
+	items, err := logic.LoadTodoItemsFromFile(rest[0])
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	beginAt, endAt, err := parseCommandRange(rest[1:], now, now)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) < 3 {
+		endAt = startOfDay(beginAt).AddDate(0, 0, webapi.DEFAULT_PLAN_DAYS)
+	}
+
+	s, taskStore, err := openCommandStore()
+	if err != nil {
+		return err
+	}
+	defer taskStore.Close()
+
+	tasks, err := taskStore.Load()
+	if err != nil {
+		return err
+	}
+
+	plan := logic.PlanTodoItems(tasks, items, beginAt, endAt, freeBusyOptionOf(s))
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATE\tBEGIN\tEND\tSUBJECT")
+
+	for _, task := range plan.Planned {
+		fmt.Fprintf(writer, "planned\t%s\t%s\t%s\n", formatCommandTime(task.BeginAt), formatCommandTime(task.EndAt), task.Subject)
+	}
+
+	for _, unplanned := range plan.Unplanned {
+		fmt.Fprintf(writer, "%s\t-\t-\t%s\n", unplanned.Reason, unplanned.Item.Subject)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if !apply {
+		if 0 < len(plan.Planned) {
+			fmt.Fprintln(w, "preview only, run again with --apply to save")
+		}
+		return nil
+	}
+
+	if len(plan.Planned) == 0 {
+		return nil
+	}
+
+	if err := taskStore.Save(append(tasks, plan.Planned...)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "saved %d tasks to %s\n", len(plan.Planned), taskStore.Location())
+	return nil
+}
+
+func shiftSchedule(w io.Writer, args []string) error {
+	option := logic.ShiftOption{Mode: logic.ShiftAll}
+	rest := []string{}
+
+	for _, arg := range args {
+		switch arg {
+		case "--chain":
+			option.Mode = logic.ShiftChain
+
+		case "--compress":
+			option.Compress = true
+
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) < 1 || 2 < len(rest) {
+		return errors.New("usage: shift <offset> [from] [--chain] [--compress]")
+	}
+
+	offset, err := time.ParseDuration(rest[0])
+	if err != nil {
+		return fmt.Errorf(`invalid offset "%s", expected such as "10m" or "-5m"`, rest[0])
+	}
+	option.Offset = offset
+
+	now := time.Now()
+	option.From = now
+
+	if 1 < len(rest) {
+		if option.From, err = parseCommandTime(rest[1], now); err != nil {
+			return err
+		}
+	}
+
+	_, taskStore, err := openCommandStore()
+	if err != nil {
+		return err
+	}
+	defer taskStore.Close()
+
+	tasks, err := taskStore.Load()
+	if err != nil {
+		return err
+	}
+
+	shiftedTasks, count, err := logic.ShiftTasks(tasks, option)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		fmt.Fprintln(w, "no tasks to shift")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "BEGIN\tEND\tSUBJECT")
+
+	for index, task := range shiftedTasks {
+		if !task.BeginAt.Equal(tasks[index].BeginAt) {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", formatCommandTime(task.BeginAt), formatCommandTime(task.EndAt), task.Subject)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if err := taskStore.Save(shiftedTasks); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "shifted %d tasks in %s\n", count, taskStore.Location())
+	return nil
+}
+
+func parseCommandRange(args []string, defaultBeginAt time.Time, now time.Time) (time.Time, time.Time, error) {
+	beginAt := defaultBeginAt
+
+	if 0 < len(args) {
+		if parsed, err := parseCommandTime(args[0], now); err != nil {
+			return beginAt, beginAt, err
+
+		} else {
+			beginAt = parsed
+		}
+	}
+
+	endAt := startOfDay(beginAt).AddDate(0, 0, 1)
+
+	if 1 < len(args) {
+		if parsed, err := parseCommandTime(args[1], now); err != nil {
+			return beginAt, endAt, err
+
+		} else {
+			endAt = parsed
+		}
+	}
+
+	if !beginAt.Before(endAt) {
+		return beginAt, endAt, errors.New("the beginning must be earlier than the end")
+	}
+
+	return beginAt, endAt, nil
+}
+
+func parseCommandTime(value string, now time.Time) (time.Time, error) {
+	if ret, err := time.Parse(time.RFC3339, value); err == nil {
+		return ret, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if ret, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return ret, nil
+		}
+	}
+
+	if clock, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		return startOfDay(now).Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute), nil
+	}
+
+	return time.Time{}, fmt.Errorf(`invalid time "%s", expected such as "15:04", "2006-01-02" or "2006-01-02T15:04"`, value)
+}
+
+func formatCommandTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
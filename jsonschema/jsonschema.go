@@ -0,0 +1,159 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const DRAFT = "https://json-schema.org/draft/2020-12/schema"
+
+type Schema map[string]any
+
+type Describer interface {
+	JSONSchema() Schema
+}
+
+type generator struct {
+	root        reflect.Type
+	refPrefix   string
+	definitions map[string]Schema
+}
+
+var describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+
+func Generate(value any, title string) Schema {
+	g := new(generator)
+	g.root = reflect.TypeOf(value)
+	g.refPrefix = "#/$defs/"
+	g.definitions = make(map[string]Schema)
+
+	ret := Schema{
+		"$schema": DRAFT,
+		"title":   title,
+	}
+
+	for key, value := range g.schemaOf(g.root, true) {
+		ret[key] = value
+	}
+
+	if 0 < len(g.definitions) {
+		ret["$defs"] = g.definitions
+	}
+
+	return ret
+}
+
+func Definitions(refPrefix string, values ...any) map[string]Schema {
+	g := new(generator)
+	g.refPrefix = refPrefix
+	g.definitions = make(map[string]Schema)
+
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		g.definitions[t.Name()] = g.schemaOf(t, true)
+	}
+
+	return g.definitions
+}
+
+func (g *generator) schemaOf(t reflect.Type, inline bool) Schema {
+	if t.Implements(describerType) {
+		return reflect.Zero(t).Interface().(Describer).JSONSchema()
+	}
+
+	if reflect.PointerTo(t).Implements(describerType) {
+		return reflect.New(t).Interface().(Describer).JSONSchema()
+	}
+
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem(), inline)
+
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+
+	case reflect.Uint8:
+		return Schema{"type": "integer", "minimum": 0, "maximum": 255}
+
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer", "minimum": 0}
+
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+
+	case reflect.String:
+		return Schema{"type": "string"}
+
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schemaOf(t.Elem(), false)}
+
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem(), false)}
+
+	case reflect.Struct:
+		return g.structSchemaOf(t, inline)
+
+	default:
+		return Schema{}
+	}
+}
+
+func (g *generator) structSchemaOf(t reflect.Type, inline bool) Schema {
+	if t == g.root && !inline {
+		return Schema{"$ref": "#"}
+	}
+
+	if !inline && t.Name() != "" {
+		if _, ok := g.definitions[t.Name()]; !ok {
+			g.definitions[t.Name()] = Schema{}
+			g.definitions[t.Name()] = g.structSchemaOf(t, true)
+		}
+
+		return Schema{"$ref": g.refPrefix + t.Name()}
+	}
+
+	properties := Schema{}
+	required := []string{}
+
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schemaOf(field.Type, false)
+
+		if field.Type.Kind() != reflect.Pointer && !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	ret := Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if 0 < len(required) {
+		ret["required"] = required
+	}
+
+	return ret
+}
@@ -0,0 +1,249 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type ValidationError struct {
+	Path   string
+	Reason string
+}
+
+type ValidationErrors []ValidationError
+
+type validator struct {
+	root map[string]any
+	errs ValidationErrors
+}
+
+func (ve ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ve.Path, ve.Reason)
+}
+
+func (ve ValidationErrors) Error() string {
+	lines := []string{}
+
+	for _, e := range ve {
+		lines = append(lines, e.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func Validate(schema Schema, jsonBytes []byte) error {
+	var root map[string]any
+	var value any
+
+	if schemaJson, err := json.Marshal(schema); err != nil {
+		return err
+
+	} else if err := json.Unmarshal(schemaJson, &root); err != nil {
+		return err
+
+	} else if err := json.Unmarshal(jsonBytes, &value); err != nil {
+		return err
+	}
+
+	v := &validator{root: root}
+	v.validate(root, value, "$")
+
+	if 0 < len(v.errs) {
+		return v.errs
+	}
+
+	return nil
+}
+
+func (v *validator) fail(path string, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) matches(schema map[string]any, value any, path string) bool {
+	nested := &validator{root: v.root}
+	nested.validate(schema, value, path)
+	return len(nested.errs) == 0
+}
+
+func (v *validator) validate(schema map[string]any, value any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if resolved, ok := v.resolve(ref); !ok {
+			v.fail(path, "unresolved reference %s", ref)
+
+		} else {
+			v.validate(resolved, value, path)
+		}
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		v.fail(path, "expected %v", types)
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			found = found || reflect.DeepEqual(candidate, value)
+		}
+
+		if !found {
+			v.fail(path, "must be one of %v", enum)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		found := false
+		for _, candidate := range anyOf {
+			if candidateSchema, ok := candidate.(map[string]any); ok {
+				found = found || v.matches(candidateSchema, value, path)
+			}
+		}
+
+		if !found {
+			v.fail(path, "matches none of anyOf")
+		}
+	}
+
+	switch typed := value.(type) {
+	case string:
+		v.validateString(schema, typed, path)
+
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && typed < minimum {
+			v.fail(path, "must be %v or greater", minimum)
+		}
+
+		if maximum, ok := schema["maximum"].(float64); ok && maximum < typed {
+			v.fail(path, "must be %v or less", maximum)
+		}
+
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for index, item := range typed {
+			if items != nil {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", path, index))
+			}
+
+			if unique, _ := schema["uniqueItems"].(bool); unique {
+				for _, other := range typed[:index] {
+					if reflect.DeepEqual(item, other) {
+						v.fail(fmt.Sprintf("%s[%d]", path, index), "is a duplicate")
+					}
+				}
+			}
+		}
+
+	case map[string]any:
+		v.validateObject(schema, typed, path)
+	}
+}
+
+func (v *validator) validateString(schema map[string]any, value string, path string) {
+	if pattern, ok := schema["pattern"].(string); ok {
+		if matched, err := regexp.MatchString(pattern, value); err != nil {
+			v.fail(path, "invalid pattern %s", pattern)
+
+		} else if !matched {
+			v.fail(path, "must match %s", pattern)
+		}
+	}
+
+	if format, _ := schema["format"].(string); format == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			v.fail(path, "must be a date-time")
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]any, value map[string]any, path string) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				v.fail(path, "%s is required", name)
+			}
+		}
+	}
+
+	for name, property := range value {
+		propertyPath := path + "." + name
+
+		if propertySchema, ok := properties[name].(map[string]any); ok {
+			v.validate(propertySchema, property, propertyPath)
+
+		} else if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			v.validate(additional, property, propertyPath)
+
+		} else if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+			v.fail(propertyPath, "is not allowed")
+		}
+	}
+}
+
+func (v *validator) resolve(ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+
+	var current any = v.root
+
+	for _, name := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current = object[strings.NewReplacer("~1", "/", "~0", "~").Replace(name)]
+	}
+
+	ret, ok := current.(map[string]any)
+	return ret, ok
+}
+
+func matchesType(types any, value any) bool {
+	names := []any{types}
+	if list, ok := types.([]any); ok {
+		names = list
+	}
+
+	for _, name := range names {
+		switch typed := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+
+		case float64:
+			if name == "number" || (name == "integer" && typed == float64(int64(typed))) {
+				return true
+			}
+
+		case string:
+			if name == "string" {
+				return true
+			}
+
+		case []any:
+			if name == "array" {
+				return true
+			}
+
+		case map[string]any:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
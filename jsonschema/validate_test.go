@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+type sampleItem struct {
+	Name  string   `json:"name"`
+	Count *int     `json:"count,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type sampleList struct {
+	Items []sampleItem `json:"items"`
+}
+
+func TestValidateGeneratedSchema(t *testing.T) {
+	schema := Generate(sampleList{}, "sample")
+
+	cases := []struct {
+		name  string
+		json  string
+		valid bool
+	}{
+		{"valid", `{"items": [{"name": "a", "count": 1, "tags": ["x"]}, {"name": "b"}]}`, true},
+		{"missing required", `{"items": [{"count": 1}]}`, false},
+		{"unknown property", `{"items": [], "extra": true}`, false},
+		{"wrong item type", `{"items": [{"name": 1}]}`, false},
+		{"fractional integer", `{"items": [{"name": "a", "count": 1.5}]}`, false},
+		{"not an object", `[]`, false},
+	}
+
+	for _, c := range cases {
+		if err := Validate(schema, []byte(c.json)); (err == nil) != c.valid {
+			t.Errorf("%s: returned %v", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time-meter/textmap"
+	winapi2 "time-meter/winapi"
+)
+
+const DEFAULT_LOCALE = "en"
+
+//go:embed embed/text.*.json
+var embedTextFs embed.FS
+
+func loadEmbedTexts(textMap textmap.TextMap) error {
+	filenames, err := embedTextFs.ReadDir("embed")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range filenames {
+		locale := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "text."), ".json")
+
+		if file, err := embedTextFs.Open(path.Join("embed", entry.Name())); err != nil {
+			return err
+
+		} else if err := textMap.LoadJson(locale, file); err != nil {
+			file.Close()
+			return err
+
+		} else {
+			file.Close()
+		}
+	}
+
+	return nil
+}
+
+func loadUserTexts(textMap textmap.TextMap) ([]string, error) {
+	textMap.ResetOverrides()
+
+	warnings := []string{}
+	errs := []error{}
+
+	for _, location := range settingsLocations() {
+		filenames, err := filepath.Glob(filepath.Join(location.dir, "text.*.json"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, filename := range filenames {
+			locale := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), "text."), ".json")
+
+			fileWarnings, err := overrideTexts(textMap, locale, filename)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+			}
+
+			for _, warning := range fileWarnings {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", filename, warning))
+			}
+		}
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+func overrideTexts(textMap textmap.TextMap, locale string, filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return textMap.Override(locale, file)
+}
+
+func detectLocale(language string) string {
+	if language != "" {
+		return language
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" && value != "C" && value != "POSIX" {
+			return value
+		}
+	}
+
+	if value := winapi2.GetUserDefaultLocaleName(); value != "" {
+		return value
+	}
+
+	return DEFAULT_LOCALE
+}
@@ -0,0 +1,188 @@
+package logic
+
+import (
+	"sort"
+	"time"
+)
+
+type Interval struct {
+	BeginAt time.Time `json:"begin_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type FreeBusy struct {
+	Busy []Interval `json:"busy"`
+	Free []Interval `json:"free"`
+}
+
+type ClockRange struct {
+	BeginAt time.Duration
+	EndAt   time.Duration
+}
+
+type FreeBusyOption struct {
+	WorkdayBeginAt time.Duration
+	WorkdayEndAt   time.Duration
+	Workdays       []time.Weekday
+	Breaks         []ClockRange
+	Buffer         time.Duration
+	MinimumSlot    time.Duration
+	Location       *time.Location
+}
+
+func (i Interval) Duration() time.Duration {
+	return i.EndAt.Sub(i.BeginAt)
+}
+
+func ComputeFreeBusy(tasks []Task, beginAt time.Time, endAt time.Time, option FreeBusyOption) FreeBusy {
+	var ret FreeBusy
+	ret.Busy = busyIntervalsOf(tasks, beginAt, endAt, option.Buffer)
+	ret.Free = []Interval{}
+
+	for _, window := range workingWindowsOf(beginAt, endAt, option) {
+		for _, free := range subtractIntervals(window, ret.Busy) {
+			if option.MinimumSlot <= free.Duration() {
+				ret.Free = append(ret.Free, free)
+			}
+		}
+	}
+
+	return ret
+}
+
+func FindSlots(tasks []Task, beginAt time.Time, endAt time.Time, duration time.Duration, option FreeBusyOption) []Interval {
+	ret := []Interval{}
+
+	for _, free := range ComputeFreeBusy(tasks, beginAt, endAt, option).Free {
+		if duration <= free.Duration() {
+			ret = append(ret, free)
+		}
+	}
+
+	return ret
+}
+
+func busyIntervalsOf(tasks []Task, beginAt time.Time, endAt time.Time, buffer time.Duration) []Interval {
+	intervals := []Interval{}
+
+	for _, task := range tasks {
+		interval := Interval{BeginAt: task.BeginAt.Add(-buffer), EndAt: task.EndAt.Add(buffer)}
+
+		if interval.BeginAt.Before(beginAt) {
+			interval.BeginAt = beginAt
+		}
+
+		if endAt.Before(interval.EndAt) {
+			interval.EndAt = endAt
+		}
+
+		if interval.BeginAt.Before(interval.EndAt) {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].BeginAt.Before(intervals[j].BeginAt)
+	})
+
+	ret := []Interval{}
+
+	for _, interval := range intervals {
+		if last := len(ret) - 1; 0 <= last && !ret[last].EndAt.Before(interval.BeginAt) {
+			if ret[last].EndAt.Before(interval.EndAt) {
+				ret[last].EndAt = interval.EndAt
+			}
+			continue
+		}
+
+		ret = append(ret, interval)
+	}
+
+	return ret
+}
+
+func workingWindowsOf(beginAt time.Time, endAt time.Time, option FreeBusyOption) []Interval {
+	ret := []Interval{}
+
+	location := option.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	workdays := make(map[time.Weekday]bool)
+	for _, weekday := range option.Workdays {
+		workdays[weekday] = true
+	}
+
+	breaks := append([]ClockRange{}, option.Breaks...)
+	sort.Slice(breaks, func(i, j int) bool {
+		return breaks[i].BeginAt < breaks[j].BeginAt
+	})
+
+	local := beginAt.In(location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+
+	for day.Before(endAt) {
+		nextDay := day.AddDate(0, 0, 1)
+
+		if len(workdays) == 0 || workdays[day.Weekday()] {
+			window := Interval{BeginAt: day.Add(option.WorkdayBeginAt), EndAt: day.Add(option.WorkdayEndAt)}
+			if option.WorkdayEndAt <= option.WorkdayBeginAt {
+				window = Interval{BeginAt: day, EndAt: nextDay}
+			}
+
+			if window.BeginAt.Before(beginAt) {
+				window.BeginAt = beginAt
+			}
+
+			if endAt.Before(window.EndAt) {
+				window.EndAt = endAt
+			}
+
+			if window.BeginAt.Before(window.EndAt) {
+				ret = append(ret, subtractIntervals(window, breakIntervalsOf(day, breaks))...)
+			}
+		}
+
+		day = nextDay
+	}
+
+	return ret
+}
+
+func breakIntervalsOf(day time.Time, breaks []ClockRange) []Interval {
+	ret := []Interval{}
+
+	for _, r := range breaks {
+		ret = append(ret, Interval{BeginAt: day.Add(r.BeginAt), EndAt: day.Add(r.EndAt)})
+	}
+
+	return ret
+}
+
+func subtractIntervals(window Interval, busy []Interval) []Interval {
+	ret := []Interval{}
+	cursor := window.BeginAt
+
+	for _, interval := range busy {
+		if !interval.EndAt.After(cursor) {
+			continue
+		}
+
+		if !interval.BeginAt.Before(window.EndAt) {
+			break
+		}
+
+		if cursor.Before(interval.BeginAt) {
+			ret = append(ret, Interval{BeginAt: cursor, EndAt: interval.BeginAt})
+		}
+
+		cursor = interval.EndAt
+	}
+
+	if cursor.Before(window.EndAt) {
+		ret = append(ret, Interval{BeginAt: cursor, EndAt: window.EndAt})
+	}
+
+	return ret
+}
@@ -0,0 +1,123 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeFreeBusy(t *testing.T) {
+	buffered := planOption()
+	buffered.Buffer = time.Minute * 15
+
+	wholeDay := planOption()
+	wholeDay.WorkdayBeginAt = 0
+	wholeDay.WorkdayEndAt = 0
+
+	inverted := planOption()
+	inverted.WorkdayBeginAt = time.Hour * 18
+	inverted.WorkdayEndAt = time.Hour * 9
+
+	cases := []struct {
+		name    string
+		tasks   []Task
+		beginAt time.Time
+		endAt   time.Time
+		option  FreeBusyOption
+		busy    []Interval
+		free    []Interval
+	}{
+		{
+			"overlapping and touching tasks are merged",
+			[]Task{
+				{BeginAt: planAt(0, 10, 0), EndAt: planAt(0, 11, 0)},
+				{BeginAt: planAt(0, 11, 30), EndAt: planAt(0, 12, 0)},
+				{BeginAt: planAt(0, 10, 30), EndAt: planAt(0, 11, 30)},
+			},
+			planAt(0, 0, 0),
+			planAt(1, 0, 0),
+			planOption(),
+			[]Interval{{planAt(0, 10, 0), planAt(0, 12, 0)}},
+			[]Interval{{planAt(0, 9, 0), planAt(0, 10, 0)}, {planAt(0, 13, 0), planAt(0, 18, 0)}},
+		},
+		{
+			"buffer widens busy intervals",
+			[]Task{{BeginAt: planAt(0, 10, 0), EndAt: planAt(0, 11, 0)}},
+			planAt(0, 0, 0),
+			planAt(1, 0, 0),
+			buffered,
+			[]Interval{{planAt(0, 9, 45), planAt(0, 11, 15)}},
+			[]Interval{{planAt(0, 9, 0), planAt(0, 9, 45)}, {planAt(0, 11, 15), planAt(0, 12, 0)}, {planAt(0, 13, 0), planAt(0, 18, 0)}},
+		},
+		{
+			"busy and free are clipped to the range",
+			[]Task{
+				{BeginAt: planAt(0, 8, 0), EndAt: planAt(0, 9, 30)},
+				{BeginAt: planAt(0, 10, 30), EndAt: planAt(0, 14, 0)},
+			},
+			planAt(0, 9, 0),
+			planAt(0, 11, 0),
+			planOption(),
+			[]Interval{{planAt(0, 9, 0), planAt(0, 9, 30)}, {planAt(0, 10, 30), planAt(0, 11, 0)}},
+			[]Interval{{planAt(0, 9, 30), planAt(0, 10, 30)}},
+		},
+		{
+			"only workdays are free",
+			nil,
+			planAt(4, 0, 0),
+			planAt(8, 0, 0),
+			planOption(),
+			[]Interval{},
+			[]Interval{
+				{planAt(4, 9, 0), planAt(4, 12, 0)},
+				{planAt(4, 13, 0), planAt(4, 18, 0)},
+				{planAt(7, 9, 0), planAt(7, 12, 0)},
+				{planAt(7, 13, 0), planAt(7, 18, 0)},
+			},
+		},
+		{
+			"slots shorter than the minimum are dropped",
+			[]Task{
+				{BeginAt: planAt(0, 9, 10), EndAt: planAt(0, 11, 45)},
+				{BeginAt: planAt(0, 13, 0), EndAt: planAt(0, 17, 50)},
+			},
+			planAt(0, 0, 0),
+			planAt(1, 0, 0),
+			planOption(),
+			[]Interval{{planAt(0, 9, 10), planAt(0, 11, 45)}, {planAt(0, 13, 0), planAt(0, 17, 50)}},
+			[]Interval{{planAt(0, 11, 45), planAt(0, 12, 0)}},
+		},
+		{
+			"unset working hours keep workdays and breaks",
+			nil,
+			planAt(4, 0, 0),
+			planAt(6, 0, 0),
+			wholeDay,
+			[]Interval{},
+			[]Interval{{planAt(4, 0, 0), planAt(4, 12, 0)}, {planAt(4, 13, 0), planAt(5, 0, 0)}},
+		},
+		{
+			"inverted working hours keep workdays and breaks",
+			nil,
+			planAt(4, 0, 0),
+			planAt(6, 0, 0),
+			inverted,
+			[]Interval{},
+			[]Interval{{planAt(4, 0, 0), planAt(4, 12, 0)}, {planAt(4, 13, 0), planAt(5, 0, 0)}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			freeBusy := ComputeFreeBusy(c.tasks, c.beginAt, c.endAt, c.option)
+
+			if !reflect.DeepEqual(freeBusy.Busy, c.busy) {
+				t.Errorf("busy %v, expected %v", freeBusy.Busy, c.busy)
+			}
+
+			if !reflect.DeepEqual(freeBusy.Free, c.free) {
+				t.Errorf("free %v, expected %v", freeBusy.Free, c.free)
+			}
+		})
+	}
+}
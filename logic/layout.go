@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"sort"
+	"time"
+)
+
+type TaskLayout struct {
+	Task    Task
+	Column  int
+	Span    int
+	Columns int
+}
+
+func LayoutTasks(tasks []Task) []TaskLayout {
+	sorted := append([]Task{}, tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].BeginAt.Equal(sorted[j].BeginAt) {
+			return sorted[i].BeginAt.Before(sorted[j].BeginAt)
+		}
+
+		return sorted[j].EndAt.Before(sorted[i].EndAt)
+	})
+
+	ret := []TaskLayout{}
+
+	for begin := 0; begin < len(sorted); {
+		end := begin + 1
+		clusterEndAt := sorted[begin].EndAt
+
+		for end < len(sorted) && sorted[end].BeginAt.Before(clusterEndAt) {
+			if clusterEndAt.Before(sorted[end].EndAt) {
+				clusterEndAt = sorted[end].EndAt
+			}
+
+			end++
+		}
+
+		ret = append(ret, layoutCluster(sorted[begin:end])...)
+		begin = end
+	}
+
+	return ret
+}
+
+func layoutCluster(tasks []Task) []TaskLayout {
+	ret := make([]TaskLayout, len(tasks))
+	columnEndAts := []time.Time{}
+	columns := [][]int{}
+
+	for index, task := range tasks {
+		column := 0
+		for column < len(columnEndAts) && task.BeginAt.Before(columnEndAts[column]) {
+			column++
+		}
+
+		if column == len(columnEndAts) {
+			columnEndAts = append(columnEndAts, time.Time{})
+			columns = append(columns, []int{})
+		}
+
+		columnEndAts[column] = task.EndAt
+		columns[column] = append(columns[column], index)
+		ret[index] = TaskLayout{Task: task, Column: column, Span: 1}
+	}
+
+	for index := range ret {
+		ret[index].Columns = len(columns)
+
+		for next := ret[index].Column + 1; next < len(columns); next++ {
+			if overlapsAny(tasks, columns[next], ret[index].Task) {
+				break
+			}
+
+			ret[index].Span++
+		}
+	}
+
+	return ret
+}
+
+func overlapsAny(tasks []Task, indexes []int, task Task) bool {
+	for _, index := range indexes {
+		if tasks[index].OverlapWith(task.BeginAt, task.EndAt) {
+			return true
+		}
+	}
+
+	return false
+}
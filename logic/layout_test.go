@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var layoutBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func layoutTask(subject string, beginMinute int, endMinute int) Task {
+	return Task{
+		Subject: subject,
+		BeginAt: layoutBaseTime.Add(time.Minute * time.Duration(beginMinute)),
+		EndAt:   layoutBaseTime.Add(time.Minute * time.Duration(endMinute)),
+	}
+}
+
+type expectedLayout struct {
+	Column  int
+	Span    int
+	Columns int
+}
+
+func TestLayoutTasks(t *testing.T) {
+	cases := []struct {
+		name     string
+		tasks    []Task
+		expected map[string]expectedLayout
+	}{
+		{
+			"empty",
+			nil,
+			map[string]expectedLayout{},
+		},
+		{
+			"disjoint clusters",
+			[]Task{layoutTask("a", 540, 600), layoutTask("b", 660, 720)},
+			map[string]expectedLayout{"a": {0, 1, 1}, "b": {0, 1, 1}},
+		},
+		{
+			"touching tasks share a column",
+			[]Task{layoutTask("a", 540, 600), layoutTask("b", 600, 660)},
+			map[string]expectedLayout{"a": {0, 1, 1}, "b": {0, 1, 1}},
+		},
+		{
+			"column packing",
+			[]Task{layoutTask("a", 540, 660), layoutTask("b", 570, 630), layoutTask("c", 630, 720)},
+			map[string]expectedLayout{"a": {0, 1, 2}, "b": {1, 1, 2}, "c": {1, 1, 2}},
+		},
+		{
+			"chained overlaps form one cluster",
+			[]Task{layoutTask("a", 540, 600), layoutTask("b", 570, 630), layoutTask("c", 615, 660), layoutTask("d", 650, 700)},
+			map[string]expectedLayout{"a": {0, 1, 2}, "b": {1, 1, 2}, "c": {0, 1, 2}, "d": {1, 1, 2}},
+		},
+		{
+			"span expansion",
+			[]Task{layoutTask("long", 540, 720), layoutTask("s1", 540, 600), layoutTask("s2", 540, 600), layoutTask("t", 600, 660)},
+			map[string]expectedLayout{"long": {0, 1, 3}, "s1": {1, 1, 3}, "s2": {2, 1, 3}, "t": {1, 2, 3}},
+		},
+		{
+			"unsorted input and longer tasks first",
+			[]Task{layoutTask("short", 540, 570), layoutTask("late", 600, 630), layoutTask("long", 540, 660)},
+			map[string]expectedLayout{"long": {0, 1, 2}, "short": {1, 1, 2}, "late": {1, 1, 2}},
+		},
+	}
+
+	for _, c := range cases {
+		layouts := LayoutTasks(c.tasks)
+
+		if len(layouts) != len(c.expected) {
+			t.Errorf("%s: laid out %d tasks, expected %d", c.name, len(layouts), len(c.expected))
+			continue
+		}
+
+		for _, layout := range layouts {
+			actual := expectedLayout{layout.Column, layout.Span, layout.Columns}
+			if expected := c.expected[layout.Task.Subject]; actual != expected {
+				t.Errorf("%s: %s is %+v, expected %+v", c.name, layout.Task.Subject, actual, expected)
+			}
+		}
+
+		assertLayoutsDoNotOverlap(t, c.name, layouts)
+	}
+}
+
+func assertLayoutsDoNotOverlap(t *testing.T, name string, layouts []TaskLayout) {
+	t.Helper()
+
+	for i, a := range layouts {
+		if a.Span < 1 || a.Columns < a.Column+a.Span {
+			t.Errorf("%s: %s spans columns %d+%d of %d", name, a.Task.Subject, a.Column, a.Span, a.Columns)
+		}
+
+		for _, b := range layouts[i+1:] {
+			if !a.Task.OverlapWith(b.Task.BeginAt, b.Task.EndAt) {
+				continue
+			}
+
+			if a.Column < b.Column+b.Span && b.Column < a.Column+a.Span {
+				t.Errorf("%s: %s and %s overlap in columns", name, a.Task.Subject, b.Task.Subject)
+			}
+		}
+	}
+}
+
+func TestLayoutRandomTasksDoNotOverlap(t *testing.T) {
+	random := rand.New(rand.NewSource(4))
+
+	for trial := 0; trial < 20; trial++ {
+		layouts := LayoutTasks(randomTasks(random, 50, time.Hour*12))
+		assertLayoutsDoNotOverlap(t, fmt.Sprintf("trial %d", trial), layouts)
+	}
+}
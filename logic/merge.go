@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type MergeMode int
+
+const (
+	MergeReplace MergeMode = iota + 1
+	MergeAppend
+	MergeUpsertById
+	MergeUpsertBySubject
+	MergeReplaceRange
+)
+
+type MergeOption struct {
+	Mode         MergeMode
+	RangeBeginAt time.Time
+	RangeEndAt   time.Time
+}
+
+func ParseMergeMode(mode string, key string) (MergeMode, error) {
+	switch mode {
+	case "", "replace":
+		return MergeReplace, nil
+
+	case "append":
+		return MergeAppend, nil
+
+	case "upsert":
+		switch key {
+		case "", "id":
+			return MergeUpsertById, nil
+
+		case "subject":
+			return MergeUpsertBySubject, nil
+
+		default:
+			return 0, fmt.Errorf(`unknown upsert key "%s"`, key)
+		}
+
+	case "replace-range":
+		return MergeReplaceRange, nil
+
+	default:
+		return 0, fmt.Errorf(`unknown merge mode "%s"`, mode)
+	}
+}
+
+func MergeTasks(currentTasks []Task, postedTasks []Task, option MergeOption) ([]Task, error) {
+	ret := []Task{}
+
+	switch option.Mode {
+	case MergeReplace:
+		ret = append(ret, postedTasks...)
+
+	case MergeAppend:
+		ret = append(ret, currentTasks...)
+		ret = append(ret, postedTasks...)
+
+	case MergeUpsertById:
+		for _, task := range postedTasks {
+			if task.ID == "" {
+				return nil, errors.New("upsert by id requires every task to have an id")
+			}
+		}
+
+		return upsertTasks(currentTasks, postedTasks, func(task Task) string {
+			return task.ID
+		})
+
+	case MergeUpsertBySubject:
+		return upsertTasks(currentTasks, postedTasks, func(task Task) string {
+			return fmt.Sprintf("%s\x00%s", task.Subject, task.BeginAt.UTC().Format(time.RFC3339Nano))
+		})
+
+	case MergeReplaceRange:
+		if !option.RangeBeginAt.Before(option.RangeEndAt) {
+			return nil, errors.New("replace-range requires a non-empty range")
+		}
+
+		for _, task := range postedTasks {
+			if !option.inRange(task) {
+				return nil, fmt.Errorf(`task "%s" is outside of the range`, task.Subject)
+			}
+		}
+
+		for _, task := range currentTasks {
+			if !option.inRange(task) {
+				ret = append(ret, task)
+			}
+		}
+
+		ret = append(ret, postedTasks...)
+
+	default:
+		return nil, fmt.Errorf("unknown merge mode %d", option.Mode)
+	}
+
+	return ret, nil
+}
+
+func (mo *MergeOption) inRange(task Task) bool {
+	return !task.BeginAt.Before(mo.RangeBeginAt) && task.BeginAt.Before(mo.RangeEndAt)
+}
+
+func upsertTasks(currentTasks []Task, postedTasks []Task, keyOf func(task Task) string) ([]Task, error) {
+	ret := append([]Task{}, currentTasks...)
+	indexes := make(map[string][]int)
+	posted := make(map[string]bool)
+
+	for index, task := range currentTasks {
+		indexes[keyOf(task)] = append(indexes[keyOf(task)], index)
+	}
+
+	for _, task := range postedTasks {
+		key := keyOf(task)
+
+		if posted[key] {
+			return nil, fmt.Errorf(`task "%s" is posted more than once`, task.Subject)
+
+		} else if 1 < len(indexes[key]) {
+			return nil, fmt.Errorf(`task "%s" matches more than one task`, task.Subject)
+		}
+
+		posted[key] = true
+
+		if found := indexes[key]; len(found) == 1 {
+			if task.Source == "" {
+				task.Source = ret[found[0]].Source
+			}
+
+			ret[found[0]] = task
+
+		} else {
+			ret = append(ret, task)
+		}
+	}
+
+	return ret, nil
+}
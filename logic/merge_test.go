@@ -0,0 +1,149 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mergeTask(id string, subject string, hour int) Task {
+	beginAt := time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)
+	return Task{ID: id, Subject: subject, BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}
+}
+
+func TestMergeTasks(t *testing.T) {
+	current := []Task{
+		mergeTask("a", "A", 9),
+		mergeTask("b", "B", 11),
+		mergeTask("", "C", 13),
+	}
+
+	renamed := mergeTask("b", "B2", 12)
+	moved := mergeTask("", "C", 13)
+	moved.EndAt = moved.EndAt.Add(time.Hour)
+
+	cases := []struct {
+		name     string
+		posted   []Task
+		option   MergeOption
+		expected []Task
+	}{
+		{
+			"replace",
+			[]Task{mergeTask("d", "D", 10)},
+			MergeOption{Mode: MergeReplace},
+			[]Task{mergeTask("d", "D", 10)},
+		},
+		{
+			"append",
+			[]Task{mergeTask("d", "D", 10)},
+			MergeOption{Mode: MergeAppend},
+			[]Task{current[0], current[1], current[2], mergeTask("d", "D", 10)},
+		},
+		{
+			"upsert by id",
+			[]Task{renamed, mergeTask("d", "D", 10)},
+			MergeOption{Mode: MergeUpsertById},
+			[]Task{current[0], renamed, current[2], mergeTask("d", "D", 10)},
+		},
+		{
+			"upsert by subject",
+			[]Task{moved, mergeTask("", "A", 10)},
+			MergeOption{Mode: MergeUpsertBySubject},
+			[]Task{current[0], current[1], moved, mergeTask("", "A", 10)},
+		},
+		{
+			"replace range",
+			[]Task{mergeTask("d", "D", 10)},
+			MergeOption{Mode: MergeReplaceRange, RangeBeginAt: current[0].EndAt, RangeEndAt: current[2].BeginAt},
+			[]Task{current[0], current[2], mergeTask("d", "D", 10)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if merged, err := MergeTasks(current, c.posted, c.option); err != nil {
+				t.Fatal(err)
+
+			} else if !reflect.DeepEqual(merged, c.expected) {
+				t.Errorf("merged %v, expected %v", merged, c.expected)
+			}
+		})
+	}
+}
+
+func TestMergeTasksKeepsSourceOfUpsertedTask(t *testing.T) {
+	current := []Task{mergeTask("a", "A", 9)}
+	current[0].Source = "b.json"
+
+	if merged, err := MergeTasks(current, []Task{mergeTask("a", "A2", 10)}, MergeOption{Mode: MergeUpsertById}); err != nil {
+		t.Fatal(err)
+
+	} else if merged[0].Source != "b.json" {
+		t.Errorf("source %q", merged[0].Source)
+	}
+}
+
+func TestMergeTasksErrors(t *testing.T) {
+	current := []Task{
+		mergeTask("a", "A", 9),
+		mergeTask("b", "A", 9),
+	}
+
+	rangeBeginAt := current[0].BeginAt
+	rangeEndAt := current[0].EndAt
+
+	cases := []struct {
+		name   string
+		posted []Task
+		option MergeOption
+	}{
+		{"unknown mode", nil, MergeOption{}},
+		{"missing range", nil, MergeOption{Mode: MergeReplaceRange}},
+		{"empty range", nil, MergeOption{Mode: MergeReplaceRange, RangeBeginAt: rangeEndAt, RangeEndAt: rangeBeginAt}},
+		{"task outside of the range", []Task{mergeTask("c", "C", 12)}, MergeOption{Mode: MergeReplaceRange, RangeBeginAt: rangeBeginAt, RangeEndAt: rangeEndAt}},
+		{"upsert by id without id", []Task{mergeTask("", "C", 12)}, MergeOption{Mode: MergeUpsertById}},
+		{"duplicate posted ids", []Task{mergeTask("c", "C", 12), mergeTask("c", "D", 13)}, MergeOption{Mode: MergeUpsertById}},
+		{"duplicate posted subjects", []Task{mergeTask("", "C", 12), mergeTask("", "C", 12)}, MergeOption{Mode: MergeUpsertBySubject}},
+		{"subject matching more than one task", []Task{mergeTask("", "A", 9)}, MergeOption{Mode: MergeUpsertBySubject}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := MergeTasks(current, c.posted, c.option); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestParseMergeMode(t *testing.T) {
+	cases := []struct {
+		mode     string
+		key      string
+		expected MergeMode
+	}{
+		{"", "", MergeReplace},
+		{"replace", "", MergeReplace},
+		{"append", "", MergeAppend},
+		{"upsert", "", MergeUpsertById},
+		{"upsert", "id", MergeUpsertById},
+		{"upsert", "subject", MergeUpsertBySubject},
+		{"replace-range", "", MergeReplaceRange},
+	}
+
+	for _, c := range cases {
+		if mode, err := ParseMergeMode(c.mode, c.key); err != nil {
+			t.Errorf("%s %s: %v", c.mode, c.key, err)
+
+		} else if mode != c.expected {
+			t.Errorf("%s %s: parsed %d, expected %d", c.mode, c.key, mode, c.expected)
+		}
+	}
+
+	for _, c := range [][2]string{{"merge", ""}, {"upsert", "title"}} {
+		if _, err := ParseMergeMode(c[0], c[1]); err == nil {
+			t.Errorf("%s %s: no error", c[0], c[1])
+		}
+	}
+}
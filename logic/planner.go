@@ -0,0 +1,228 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+	"time-meter/jsonschema"
+)
+
+type TodoItem struct {
+	ID              string
+	Subject         string
+	Estimate        time.Duration
+	Priority        int
+	Deadline        time.Time
+	EarliestStartAt time.Time
+}
+
+type UnplannedReason string
+
+const (
+	UNPLANNED_INVALID_ESTIMATE UnplannedReason = "invalid_estimate"
+	UNPLANNED_NO_SLOT          UnplannedReason = "no_slot"
+	UNPLANNED_DEADLINE         UnplannedReason = "deadline"
+)
+
+type UnplannedItem struct {
+	Item   TodoItem        `json:"item"`
+	Reason UnplannedReason `json:"reason"`
+}
+
+type Plan struct {
+	Planned   []Task          `json:"planned"`
+	Unplanned []UnplannedItem `json:"unplanned"`
+}
+
+type todoItemJson struct {
+	ID              string     `json:"id,omitempty"`
+	Subject         string     `json:"subject"`
+	Estimate        string     `json:"estimate"`
+	Priority        int        `json:"priority,omitempty"`
+	Deadline        *time.Time `json:"deadline,omitempty"`
+	EarliestStartAt *time.Time `json:"earliest_start_at,omitempty"`
+}
+
+func (ti TodoItem) MarshalJSON() ([]byte, error) {
+	encoded := todoItemJson{
+		ID:       ti.ID,
+		Subject:  ti.Subject,
+		Estimate: ti.Estimate.String(),
+		Priority: ti.Priority,
+	}
+
+	if !ti.Deadline.IsZero() {
+		encoded.Deadline = &ti.Deadline
+	}
+
+	if !ti.EarliestStartAt.IsZero() {
+		encoded.EarliestStartAt = &ti.EarliestStartAt
+	}
+
+	return json.Marshal(encoded)
+}
+
+func (ti *TodoItem) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type": "object",
+		"properties": jsonschema.Schema{
+			"id":      jsonschema.Schema{"type": "string"},
+			"subject": jsonschema.Schema{"type": "string"},
+			"estimate": jsonschema.Schema{
+				"type":        "string",
+				"description": "Go-style duration",
+				"examples":    []string{"30m", "1h30m"},
+			},
+			"priority": jsonschema.Schema{
+				"type":        "integer",
+				"description": "Higher is planned first",
+			},
+			"deadline":          jsonschema.Schema{"type": "string", "format": "date-time"},
+			"earliest_start_at": jsonschema.Schema{"type": "string", "format": "date-time"},
+		},
+		"required":             []string{"subject", "estimate"},
+		"additionalProperties": false,
+	}
+}
+
+func (ur *UnplannedReason) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type": "string",
+		"enum": []UnplannedReason{UNPLANNED_INVALID_ESTIMATE, UNPLANNED_NO_SLOT, UNPLANNED_DEADLINE},
+	}
+}
+
+func (ti *TodoItem) UnmarshalJSON(data []byte) error {
+	var decoded todoItemJson
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	estimate, err := time.ParseDuration(decoded.Estimate)
+	if err != nil {
+		return fmt.Errorf(`invalid estimate "%s", expected such as "30m" or "1h30m"`, decoded.Estimate)
+	}
+
+	ti.ID = decoded.ID
+	ti.Subject = decoded.Subject
+	ti.Estimate = estimate
+	ti.Priority = decoded.Priority
+	ti.Deadline = time.Time{}
+	ti.EarliestStartAt = time.Time{}
+
+	if decoded.Deadline != nil {
+		ti.Deadline = *decoded.Deadline
+	}
+
+	if decoded.EarliestStartAt != nil {
+		ti.EarliestStartAt = *decoded.EarliestStartAt
+	}
+
+	return nil
+}
+
+func PlanTodoItems(tasks []Task, items []TodoItem, beginAt time.Time, endAt time.Time, option FreeBusyOption) Plan {
+	ret := Plan{Planned: []Task{}, Unplanned: []UnplannedItem{}}
+
+	ordered := append([]TodoItem{}, items...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return todoItemLess(ordered[i], ordered[j])
+	})
+
+	occupied := append([]Task{}, tasks...)
+
+	for _, item := range ordered {
+		if item.Estimate <= 0 {
+			ret.Unplanned = append(ret.Unplanned, UnplannedItem{Item: item, Reason: UNPLANNED_INVALID_ESTIMATE})
+			continue
+		}
+
+		itemBeginAt := beginAt
+		if itemBeginAt.Before(item.EarliestStartAt) {
+			itemBeginAt = item.EarliestStartAt
+		}
+
+		itemEndAt := endAt
+		if !item.Deadline.IsZero() && item.Deadline.Before(itemEndAt) {
+			itemEndAt = item.Deadline
+		}
+
+		slot, ok := firstSlotOf(occupied, itemBeginAt, itemEndAt, item.Estimate, option)
+		if !ok {
+			reason := UNPLANNED_NO_SLOT
+			if itemEndAt.Before(endAt) {
+				if _, ok := firstSlotOf(occupied, itemBeginAt, endAt, item.Estimate, option); ok {
+					reason = UNPLANNED_DEADLINE
+				}
+			}
+
+			ret.Unplanned = append(ret.Unplanned, UnplannedItem{Item: item, Reason: reason})
+			continue
+		}
+
+		task := Task{
+			ID:      item.ID,
+			Subject: item.Subject,
+			BeginAt: slot.BeginAt,
+			EndAt:   slot.BeginAt.Add(item.Estimate),
+		}
+
+		ret.Planned = append(ret.Planned, task)
+		occupied = append(occupied, task)
+	}
+
+	sort.SliceStable(ret.Planned, func(i, j int) bool {
+		return ret.Planned[i].BeginAt.Before(ret.Planned[j].BeginAt)
+	})
+
+	return ret
+}
+
+func firstSlotOf(tasks []Task, beginAt time.Time, endAt time.Time, duration time.Duration, option FreeBusyOption) (Interval, bool) {
+	if !beginAt.Before(endAt) {
+		return Interval{}, false
+	}
+
+	slots := FindSlots(tasks, beginAt, endAt, duration, option)
+	if len(slots) == 0 {
+		return Interval{}, false
+	}
+
+	return slots[0], true
+}
+
+func todoItemLess(a TodoItem, b TodoItem) bool {
+	if a.Priority != b.Priority {
+		return b.Priority < a.Priority
+	}
+
+	if !a.Deadline.Equal(b.Deadline) {
+		if a.Deadline.IsZero() || b.Deadline.IsZero() {
+			return b.Deadline.IsZero()
+		}
+
+		return a.Deadline.Before(b.Deadline)
+	}
+
+	if !a.EarliestStartAt.Equal(b.EarliestStartAt) {
+		return a.EarliestStartAt.Before(b.EarliestStartAt)
+	}
+
+	return false
+}
+
+func LoadTodoItemsFromFile(filename string) ([]TodoItem, error) {
+	jsonBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []TodoItem
+	if err := json.Unmarshal(jsonBytes, &ret); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return ret, nil
+}
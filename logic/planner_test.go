@@ -0,0 +1,169 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var planMonday = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func planAt(day int, hour int, minute int) time.Time {
+	return planMonday.AddDate(0, 0, day).Add(time.Hour*time.Duration(hour) + time.Minute*time.Duration(minute))
+}
+
+func planOption() FreeBusyOption {
+	return FreeBusyOption{
+		WorkdayBeginAt: time.Hour * 9,
+		WorkdayEndAt:   time.Hour * 18,
+		Workdays:       []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Breaks:         []ClockRange{{BeginAt: time.Hour * 12, EndAt: time.Hour * 13}},
+		MinimumSlot:    time.Minute * 15,
+		Location:       time.UTC,
+	}
+}
+
+type plannedSpan struct {
+	ID      string
+	BeginAt time.Time
+	EndAt   time.Time
+}
+
+func plannedSpansOf(plan Plan) []plannedSpan {
+	ret := []plannedSpan{}
+
+	for _, task := range plan.Planned {
+		ret = append(ret, plannedSpan{task.ID, task.BeginAt, task.EndAt})
+	}
+
+	return ret
+}
+
+func TestPlanTodoItemsOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		tasks    []Task
+		items    []TodoItem
+		expected []plannedSpan
+	}{
+		{
+			"priority first",
+			nil,
+			[]TodoItem{
+				{ID: "low", Estimate: time.Hour * 2, Priority: 1},
+				{ID: "high", Estimate: time.Hour * 2, Priority: 5},
+			},
+			[]plannedSpan{
+				{"high", planAt(0, 9, 0), planAt(0, 11, 0)},
+				{"low", planAt(0, 13, 0), planAt(0, 15, 0)},
+			},
+		},
+		{
+			"earlier deadline first and no deadline last",
+			nil,
+			[]TodoItem{
+				{ID: "none", Estimate: time.Hour},
+				{ID: "tuesday", Estimate: time.Hour, Deadline: planAt(1, 18, 0)},
+				{ID: "monday", Estimate: time.Hour, Deadline: planAt(0, 12, 0)},
+			},
+			[]plannedSpan{
+				{"monday", planAt(0, 9, 0), planAt(0, 10, 0)},
+				{"tuesday", planAt(0, 10, 0), planAt(0, 11, 0)},
+				{"none", planAt(0, 11, 0), planAt(0, 12, 0)},
+			},
+		},
+		{
+			"earlier start first",
+			nil,
+			[]TodoItem{
+				{ID: "later", Estimate: time.Hour, EarliestStartAt: planAt(0, 10, 0)},
+				{ID: "earlier", Estimate: time.Hour * 2, EarliestStartAt: planAt(0, 9, 0)},
+			},
+			[]plannedSpan{
+				{"earlier", planAt(0, 9, 0), planAt(0, 11, 0)},
+				{"later", planAt(0, 11, 0), planAt(0, 12, 0)},
+			},
+		},
+		{
+			"around existing tasks and breaks",
+			[]Task{{ID: "meeting", BeginAt: planAt(0, 9, 0), EndAt: planAt(0, 11, 30)}},
+			[]TodoItem{
+				{ID: "long", Estimate: time.Hour},
+				{ID: "short", Estimate: time.Minute * 30},
+			},
+			[]plannedSpan{
+				{"short", planAt(0, 11, 30), planAt(0, 12, 0)},
+				{"long", planAt(0, 13, 0), planAt(0, 14, 0)},
+			},
+		},
+		{
+			"skips the weekend",
+			[]Task{{ID: "friday", BeginAt: planAt(4, 9, 0), EndAt: planAt(4, 18, 0)}},
+			[]TodoItem{{ID: "item", Estimate: time.Hour, EarliestStartAt: planAt(4, 9, 0)}},
+			[]plannedSpan{{"item", planAt(7, 9, 0), planAt(7, 10, 0)}},
+		},
+	}
+
+	for _, c := range cases {
+		plan := PlanTodoItems(c.tasks, c.items, planAt(0, 0, 0), planAt(14, 0, 0), planOption())
+
+		if len(plan.Unplanned) != 0 {
+			t.Errorf("%s: unplanned %v", c.name, plan.Unplanned)
+		}
+
+		if actual := plannedSpansOf(plan); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: planned %v, expected %v", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestPlanTodoItemsUnplannedReasons(t *testing.T) {
+	items := []TodoItem{
+		{ID: "zero", Estimate: 0},
+		{ID: "negative", Estimate: -time.Hour},
+		{ID: "too long", Estimate: time.Hour * 6},
+		{ID: "missed", Estimate: time.Hour, Deadline: planAt(0, 9, 30)},
+		{ID: "fits", Estimate: time.Hour, Deadline: planAt(0, 18, 0)},
+	}
+
+	plan := PlanTodoItems(nil, items, planAt(0, 0, 0), planAt(1, 0, 0), planOption())
+
+	reasons := make(map[string]UnplannedReason)
+	for _, unplanned := range plan.Unplanned {
+		reasons[unplanned.Item.ID] = unplanned.Reason
+	}
+
+	expected := map[string]UnplannedReason{
+		"zero":     UNPLANNED_INVALID_ESTIMATE,
+		"negative": UNPLANNED_INVALID_ESTIMATE,
+		"too long": UNPLANNED_NO_SLOT,
+		"missed":   UNPLANNED_DEADLINE,
+	}
+
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("unplanned %v, expected %v", reasons, expected)
+	}
+
+	if actual := plannedSpansOf(plan); !reflect.DeepEqual(actual, []plannedSpan{{"fits", planAt(0, 9, 0), planAt(0, 10, 0)}}) {
+		t.Errorf("planned %v", actual)
+	}
+}
+
+func TestComputeFreeBusyExcludesBreaks(t *testing.T) {
+	option := planOption()
+	option.Breaks = append(option.Breaks, ClockRange{BeginAt: time.Hour * 15, EndAt: time.Hour*15 + time.Minute*10})
+
+	tasks := []Task{{BeginAt: planAt(0, 10, 0), EndAt: planAt(0, 11, 0)}}
+	freeBusy := ComputeFreeBusy(tasks, planAt(0, 0, 0), planAt(1, 0, 0), option)
+
+	expected := []Interval{
+		{planAt(0, 9, 0), planAt(0, 10, 0)},
+		{planAt(0, 11, 0), planAt(0, 12, 0)},
+		{planAt(0, 13, 0), planAt(0, 15, 0)},
+		{planAt(0, 15, 10), planAt(0, 18, 0)},
+	}
+
+	if !reflect.DeepEqual(freeBusy.Free, expected) {
+		t.Errorf("free %v, expected %v", freeBusy.Free, expected)
+	}
+}
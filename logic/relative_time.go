@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"time"
+)
+
+type RelativeTimeKind int
+
+const (
+	RelativeUpcoming RelativeTimeKind = iota
+	RelativeOngoing
+	RelativeEnded
+)
+
+type RelativeTime struct {
+	Kind     RelativeTimeKind
+	Duration time.Duration
+}
+
+func RelativeTimeOf(task Task, now time.Time) RelativeTime {
+	if now.Before(task.BeginAt) {
+		return RelativeTime{Kind: RelativeUpcoming, Duration: task.BeginAt.Sub(now)}
+
+	} else if now.Before(task.EndAt) {
+		return RelativeTime{Kind: RelativeOngoing, Duration: task.EndAt.Sub(now)}
+
+	} else {
+		return RelativeTime{Kind: RelativeEnded, Duration: now.Sub(task.EndAt)}
+	}
+}
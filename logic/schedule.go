@@ -0,0 +1,204 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata"
+)
+
+const SCHEDULE_VERSION = 2
+
+type Schedule struct {
+	Schema   string `json:"$schema,omitempty"`
+	Version  int    `json:"version"`
+	Timezone string `json:"timezone,omitempty"`
+	Tasks    []Task `json:"tasks"`
+}
+
+type scheduleDecoder func(jsonBytes []byte) (Schedule, error)
+
+type scheduleMigration func(schedule *Schedule) error
+
+var scheduleDecoders = map[int]scheduleDecoder{
+	1: decodeScheduleV1,
+	2: decodeScheduleV2,
+}
+
+var scheduleMigrations = map[int]scheduleMigration{
+	1: migrateScheduleV1,
+}
+
+func NewSchedule(tasks []Task) Schedule {
+	var ret Schedule
+	ret.Version = SCHEDULE_VERSION
+	ret.Tasks = append([]Task{}, tasks...)
+	return ret
+}
+
+func LoadScheduleFromFile(filename string) (Schedule, error) {
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		return Schedule{}, err
+
+	} else {
+		return DecodeSchedule(jsonBytes)
+	}
+}
+
+func SaveScheduleToFile(filename string, schedule Schedule) error {
+	if err := schedule.Migrate(); err != nil {
+		return err
+	}
+
+	if schedule.Tasks == nil {
+		schedule.Tasks = []Task{}
+	}
+
+	jsonBuffer := bytes.NewBuffer(nil)
+
+	encoder := json.NewEncoder(jsonBuffer)
+	encoder.SetIndent("", "\t")
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(schedule); err != nil {
+		return err
+
+	} else if err := os.WriteFile(filename, jsonBuffer.Bytes(), os.ModePerm); err != nil {
+		return err
+
+	} else {
+		return nil
+	}
+}
+
+func DecodeSchedule(jsonBytes []byte) (Schedule, error) {
+	version := 1
+
+	if trimmed := bytes.TrimSpace(jsonBytes); !bytes.HasPrefix(trimmed, []byte("[")) {
+		var header struct {
+			Version *int `json:"version"`
+		}
+
+		if err := json.Unmarshal(trimmed, &header); err != nil {
+			return Schedule{}, err
+
+		} else if header.Version == nil {
+			return Schedule{}, errors.New(`"version" is missing`)
+
+		} else {
+			version = *header.Version
+		}
+	}
+
+	decoder, ok := scheduleDecoders[version]
+	if !ok {
+		return Schedule{}, fmt.Errorf("unsupported schedule version %d", version)
+	}
+
+	ret, err := decoder(jsonBytes)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	ret.Version = version
+
+	return ret, nil
+}
+
+func (s *Schedule) Migrate() error {
+	for s.Version < SCHEDULE_VERSION {
+		migration, ok := scheduleMigrations[s.Version]
+		if !ok {
+			return fmt.Errorf("no migration from schedule version %d", s.Version)
+		}
+
+		if err := migration(s); err != nil {
+			return err
+		}
+	}
+
+	if SCHEDULE_VERSION < s.Version {
+		return fmt.Errorf("unsupported schedule version %d", s.Version)
+	}
+
+	return nil
+}
+
+func decodeScheduleV1(jsonBytes []byte) (Schedule, error) {
+	var ret Schedule
+
+	if err := json.NewDecoder(bytes.NewReader(jsonBytes)).Decode(&ret.Tasks); err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+func decodeScheduleV2(jsonBytes []byte) (Schedule, error) {
+	var envelope struct {
+		Schedule
+		Tasks []struct {
+			Task
+			BeginAt string `json:"begin_at"`
+			EndAt   string `json:"end_at"`
+		} `json:"tasks"`
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(jsonBytes)).Decode(&envelope); err != nil {
+		return Schedule{}, err
+	}
+
+	ret := envelope.Schedule
+	ret.Tasks = []Task{}
+
+	location := time.Local
+	if ret.Timezone != "" {
+		if loaded, err := time.LoadLocation(ret.Timezone); err != nil {
+			return Schedule{}, err
+
+		} else {
+			location = loaded
+		}
+	}
+
+	for index, entry := range envelope.Tasks {
+		task := entry.Task
+
+		if beginAt, err := parseTime(entry.BeginAt, location); err != nil {
+			return Schedule{}, fmt.Errorf("tasks[%d].begin_at: %w", index, err)
+
+		} else if endAt, err := parseTime(entry.EndAt, location); err != nil {
+			return Schedule{}, fmt.Errorf("tasks[%d].end_at: %w", index, err)
+
+		} else {
+			task.BeginAt = beginAt
+			task.EndAt = endAt
+		}
+
+		ret.Tasks = append(ret.Tasks, task)
+	}
+
+	return ret, nil
+}
+
+func migrateScheduleV1(schedule *Schedule) error {
+	schedule.Version = 2
+	return nil
+}
+
+func parseTime(value string, location *time.Location) (time.Time, error) {
+	if ret, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ret, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if ret, err := time.ParseInLocation(layout, value, location); err == nil {
+			return ret, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(`invalid time "%s"`, value)
+}
@@ -0,0 +1,296 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const DEFAULT_SCHEDULE_FILENAME = "schedule.json"
+
+type ScheduleSet struct {
+	mutex    sync.Mutex
+	patterns []string
+	files    []string
+	tasks    map[string][]Task
+	failures map[string]error
+}
+
+type ScheduleFailure struct {
+	Filename string
+	Err      error
+}
+
+func NewScheduleSet(patterns []string) *ScheduleSet {
+	ret := new(ScheduleSet)
+	ret.patterns = append([]string{}, patterns...)
+	ret.tasks = make(map[string][]Task)
+	ret.failures = make(map[string]error)
+	return ret
+}
+
+func (ss *ScheduleSet) Patterns() []string {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return append([]string{}, ss.patterns...)
+}
+
+func (ss *ScheduleSet) SetPatterns(patterns []string) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ss.patterns = append([]string{}, patterns...)
+}
+
+func (ss *ScheduleSet) WatchPatterns() []string {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ret := []string{}
+
+	for _, pattern := range ss.patterns {
+		if isDir(pattern) {
+			ret = append(ret, filepath.Join(pattern, "*.json"))
+
+		} else {
+			ret = append(ret, pattern)
+		}
+	}
+
+	return ret
+}
+
+func (ss *ScheduleSet) Primary() string {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if len(ss.patterns) == 0 {
+		return DEFAULT_SCHEDULE_FILENAME
+	}
+
+	pattern := ss.patterns[0]
+
+	if matches := expandSchedulePattern(pattern); 0 < len(matches) {
+		return matches[0]
+
+	} else if isDir(pattern) {
+		return filepath.Join(pattern, DEFAULT_SCHEDULE_FILENAME)
+
+	} else if isGlob(pattern) {
+		return filepath.Join(filepath.Dir(pattern), DEFAULT_SCHEDULE_FILENAME)
+
+	} else {
+		return pattern
+	}
+}
+
+func (ss *ScheduleSet) ReloadAll() {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ss.expand()
+
+	for _, filename := range ss.files {
+		ss.load(filename)
+	}
+}
+
+func (ss *ScheduleSet) Reload(filename string) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ss.expand()
+
+	for _, known := range ss.files {
+		if _, loaded := ss.tasks[known]; !loaded && ss.failures[known] == nil || samePath(known, filename) {
+			ss.load(known)
+		}
+	}
+}
+
+func (ss *ScheduleSet) Tasks() []Task {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ret := []Task{}
+
+	for _, filename := range ss.files {
+		ret = append(ret, ss.tasks[filename]...)
+	}
+
+	return ret
+}
+
+func (ss *ScheduleSet) Failures() []ScheduleFailure {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	ret := []ScheduleFailure{}
+
+	for _, filename := range ss.files {
+		if err := ss.failures[filename]; err != nil {
+			ret = append(ret, ScheduleFailure{Filename: filename, Err: err})
+		}
+	}
+
+	return ret
+}
+
+func (ss *ScheduleSet) Save(tasks []Task) error {
+	primary := ss.Primary()
+
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	grouped := make(map[string][]Task)
+	for _, filename := range ss.files {
+		if ss.failures[filename] == nil {
+			grouped[filename] = []Task{}
+		}
+	}
+
+	for _, task := range tasks {
+		task.Source = ss.sourceOf(task, primary)
+		grouped[task.Source] = append(grouped[task.Source], task)
+	}
+
+	for filename := range grouped {
+		if err := ss.failures[filename]; err != nil {
+			return fmt.Errorf("%s: cannot be saved until it loads again: %w", filename, err)
+		}
+	}
+
+	for filename, fileTasks := range grouped {
+		if current, ok := ss.tasks[filename]; ok && reflect.DeepEqual(current, fileTasks) {
+			continue
+		}
+
+		if err := SaveTasksFromFile(filename, fileTasks); err != nil {
+			return err
+		}
+
+		ss.tasks[filename] = fileTasks
+		delete(ss.failures, filename)
+	}
+
+	ss.expand()
+
+	return nil
+}
+
+func (ss *ScheduleSet) sourceOf(task Task, primary string) string {
+	if task.Source != "" {
+		for _, filename := range ss.files {
+			if samePath(filename, task.Source) {
+				return filename
+			}
+		}
+	}
+
+	for _, filename := range ss.files {
+		for _, known := range ss.tasks[filename] {
+			if task.ID != "" && task.ID == known.ID {
+				return filename
+
+			} else if task.ID == "" && task.Subject == known.Subject && task.BeginAt.Equal(known.BeginAt) && task.EndAt.Equal(known.EndAt) {
+				return filename
+			}
+		}
+	}
+
+	for _, filename := range ss.files {
+		if samePath(filename, primary) {
+			return filename
+		}
+	}
+
+	return primary
+}
+
+func (ss *ScheduleSet) expand() {
+	ss.files = []string{}
+	known := make(map[string]bool)
+
+	for _, pattern := range ss.patterns {
+		for _, filename := range expandSchedulePattern(pattern) {
+			if fullpath, _ := filepath.Abs(filename); !known[fullpath] {
+				known[fullpath] = true
+				ss.files = append(ss.files, filename)
+			}
+		}
+	}
+
+	for filename := range ss.tasks {
+		if fullpath, _ := filepath.Abs(filename); !known[fullpath] {
+			delete(ss.tasks, filename)
+		}
+	}
+
+	for filename := range ss.failures {
+		if fullpath, _ := filepath.Abs(filename); !known[fullpath] {
+			delete(ss.failures, filename)
+		}
+	}
+}
+
+func (ss *ScheduleSet) load(filename string) {
+	tasks, err := LoadTasksFromFile(filename)
+	if os.IsNotExist(err) {
+		tasks, err = []Task{}, nil
+	}
+
+	if err != nil {
+		delete(ss.tasks, filename)
+		ss.failures[filename] = err
+		return
+	}
+
+	for index := range tasks {
+		tasks[index].Source = filename
+	}
+
+	ss.tasks[filename] = tasks
+	delete(ss.failures, filename)
+}
+
+func expandSchedulePattern(pattern string) []string {
+	if isDir(pattern) {
+		pattern = filepath.Join(pattern, "*.json")
+
+	} else if !isGlob(pattern) {
+		return []string{filepath.Clean(pattern)}
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return []string{}
+	}
+
+	ret := []string{}
+	for _, match := range matches {
+		if !isDir(match) {
+			ret = append(ret, match)
+		}
+	}
+
+	return ret
+}
+
+func isDir(filename string) bool {
+	fileInfo, err := os.Stat(filename)
+	return err == nil && fileInfo.IsDir()
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+func samePath(a string, b string) bool {
+	fullpathA, errA := filepath.Abs(a)
+	fullpathB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && strings.EqualFold(fullpathA, fullpathB)
+}
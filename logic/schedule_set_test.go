@@ -0,0 +1,134 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScheduleSetSaveKeepsFailedFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	broken := filepath.Join(dir, "broken.json")
+	brokenJson := []byte(`{"version": 2, "tasks": [`)
+
+	beginAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	if err := SaveScheduleToFile(good, NewSchedule([]Task{{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(broken, brokenJson, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ss := NewScheduleSet([]string{good, broken})
+	ss.ReloadAll()
+
+	if failures := ss.Failures(); len(failures) != 1 || failures[0].Filename != broken {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+
+	tasks := ss.Tasks()
+	tasks = append(tasks, Task{ID: "b", Subject: "B", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour), Source: broken})
+
+	if err := ss.Save(tasks); err == nil {
+		t.Error("saving into a failed file succeeded")
+	}
+
+	if jsonBytes, err := os.ReadFile(broken); err != nil {
+		t.Fatal(err)
+
+	} else if string(jsonBytes) != string(brokenJson) {
+		t.Errorf("the failed file was overwritten: %s", jsonBytes)
+	}
+
+	if err := ss.Save(ss.Tasks()); err != nil {
+		t.Errorf("saving only the loaded files failed: %v", err)
+	}
+}
+
+func TestSaveTasksFromFileKeepsUnparsableFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+	brokenJson := []byte(`not json`)
+
+	if err := os.WriteFile(filename, brokenJson, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveTasksFromFile(filename, []Task{}); err == nil {
+		t.Error("saving over an unparsable file succeeded")
+	}
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if string(jsonBytes) != string(brokenJson) {
+		t.Errorf("the unparsable file was overwritten: %s", jsonBytes)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if err := SaveTasksFromFile(missing, []Task{}); err != nil {
+		t.Errorf("saving a new file failed: %v", err)
+	}
+}
+
+func TestScheduleSetSavesIntoMissingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+
+	ss := NewScheduleSet([]string{filename})
+	ss.ReloadAll()
+
+	if failures := ss.Failures(); len(failures) != 0 {
+		t.Fatalf("a missing file failed to load: %v", failures)
+	}
+
+	if tasks := ss.Tasks(); len(tasks) != 0 {
+		t.Fatalf("a missing file has tasks %v", tasks)
+	}
+
+	beginAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	if err := ss.Save([]Task{{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if tasks, err := LoadTasksFromFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if len(tasks) != 1 || tasks[0].ID != "a" {
+		t.Errorf("saved %v", tasks)
+	}
+}
+
+func TestScheduleSetKeepsFileOfUpsertedTask(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "a.json")
+	secondary := filepath.Join(dir, "b.json")
+
+	beginAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	if err := SaveScheduleToFile(secondary, NewSchedule([]Task{{Subject: "B", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}})); err != nil {
+		t.Fatal(err)
+	}
+
+	ss := NewScheduleSet([]string{primary, secondary})
+	ss.ReloadAll()
+
+	posted := []Task{{Subject: "B", BeginAt: beginAt, EndAt: beginAt.Add(2 * time.Hour)}}
+	if tasks, err := MergeTasks(ss.Tasks(), posted, MergeOption{Mode: MergeUpsertBySubject}); err != nil {
+		t.Fatal(err)
+
+	} else if err := ss.Save(tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	if tasks, err := LoadTasksFromFile(secondary); err != nil {
+		t.Fatal(err)
+
+	} else if len(tasks) != 1 || !tasks[0].EndAt.Equal(beginAt.Add(2*time.Hour)) {
+		t.Errorf("%s has %v", secondary, tasks)
+	}
+
+	if tasks, err := LoadTasksFromFile(primary); err == nil && len(tasks) != 0 {
+		t.Errorf("the upserted task moved into %s: %v", primary, tasks)
+	}
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"time-meter/jsonschema"
+)
+
+const TASK_TIME_PATTERN = `^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})|[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?)$`
+
+func ScheduleJSONSchema() jsonschema.Schema {
+	envelope := jsonschema.Generate(Schedule{}, "TimeMeter schedule")
+	definitions := envelope["$defs"].(map[string]jsonschema.Schema)
+
+	ret := jsonschema.Schema{
+		"$schema": envelope["$schema"],
+		"title":   envelope["title"],
+		"$defs":   definitions,
+	}
+
+	delete(envelope, "$schema")
+	delete(envelope, "title")
+	delete(envelope, "$defs")
+
+	definitions["TaskV1"] = definitions["Task"]
+	definitions["Task"] = taskSchemaWithLocalTimes(definitions["Task"])
+
+	ret["anyOf"] = []jsonschema.Schema{
+		envelope,
+		{
+			"type":       "array",
+			"items":      jsonschema.Schema{"$ref": "#/$defs/TaskV1"},
+			"deprecated": true,
+		},
+	}
+
+	return ret
+}
+
+func taskSchemaWithLocalTimes(task jsonschema.Schema) jsonschema.Schema {
+	ret := jsonschema.Schema{}
+	for key, value := range task {
+		ret[key] = value
+	}
+
+	properties := jsonschema.Schema{}
+	for key, value := range task["properties"].(jsonschema.Schema) {
+		properties[key] = value
+	}
+
+	for _, key := range []string{"begin_at", "end_at"} {
+		properties[key] = jsonschema.Schema{
+			"type":        "string",
+			"pattern":     TASK_TIME_PATTERN,
+			"description": "RFC 3339 date-time, or a local time without an offset in the schedule timezone",
+			"examples":    []string{"2024-01-01T09:00:00+09:00", "2024-01-01T09:00", "2024-01-01 09:00"},
+		}
+	}
+
+	ret["properties"] = properties
+
+	return ret
+}
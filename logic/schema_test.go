@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"time-meter/jsonschema"
+)
+
+func TestSavedScheduleMatchesSchema(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+	beginAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	schedule := NewSchedule([]Task{
+		{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)},
+		{Subject: "B", BeginAt: beginAt.UTC(), EndAt: beginAt.Add(time.Minute * 90).UTC()},
+	})
+	schedule.Timezone = "Asia/Tokyo"
+
+	if err := SaveScheduleToFile(filename, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if err := jsonschema.Validate(ScheduleJSONSchema(), jsonBytes); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScheduleSchemaAgreesWithDecoder(t *testing.T) {
+	cases := []struct {
+		name  string
+		json  string
+		valid bool
+	}{
+		{"offset", `{"version": 2, "tasks": [{"subject": "A", "begin_at": "2024-01-01T09:00:00+09:00", "end_at": "2024-01-01T10:00:00.5Z"}]}`, true},
+		{"local times", `{"version": 2, "tasks": [{"subject": "A", "begin_at": "2024-01-01T09:00", "end_at": "2024-01-01 10:00:30"}]}`, true},
+		{"legacy array", `[{"subject": "A", "begin_at": "2024-01-01T09:00:00Z", "end_at": "2024-01-01T10:00:00Z"}]`, true},
+		{"date only", `{"version": 2, "tasks": [{"subject": "A", "begin_at": "2024-01-01", "end_at": "2024-01-02"}]}`, false},
+		{"offset without seconds", `{"version": 2, "tasks": [{"subject": "A", "begin_at": "2024-01-01T09:00+09:00", "end_at": "2024-01-01T10:00+09:00"}]}`, false},
+		{"legacy local times", `[{"subject": "A", "begin_at": "2024-01-01T09:00", "end_at": "2024-01-01T10:00"}]`, false},
+	}
+
+	for _, c := range cases {
+		_, decodeErr := DecodeSchedule([]byte(c.json))
+		schemaErr := jsonschema.Validate(ScheduleJSONSchema(), []byte(c.json))
+
+		if (decodeErr == nil) != c.valid {
+			t.Errorf("%s: decoder returned %v", c.name, decodeErr)
+		}
+
+		if (schemaErr == nil) != c.valid {
+			t.Errorf("%s: schema returned %v", c.name, schemaErr)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package logic
+
+type Selection struct {
+	Active    string   `json:"active"`
+	Available []string `json:"available"`
+}
+
+type SelectionRequest struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type ShiftMode int
+
+const (
+	ShiftAll ShiftMode = iota + 1
+	ShiftChain
+)
+
+type ShiftOption struct {
+	Mode     ShiftMode
+	From     time.Time
+	Offset   time.Duration
+	Compress bool
+}
+
+func ParseShiftMode(mode string) (ShiftMode, error) {
+	switch mode {
+	case "", "all":
+		return ShiftAll, nil
+
+	case "chain":
+		return ShiftChain, nil
+
+	default:
+		return 0, fmt.Errorf(`unknown shift mode "%s"`, mode)
+	}
+}
+
+func ShiftTasks(tasks []Task, option ShiftOption) ([]Task, int, error) {
+	if option.Offset == 0 {
+		return nil, 0, errors.New("the offset must not be zero")
+	}
+
+	if option.Compress && option.Offset < 0 {
+		return nil, 0, errors.New("compressing requires a positive offset")
+	}
+
+	ret := append([]Task{}, tasks...)
+
+	indices := []int{}
+	for index, task := range ret {
+		if !task.BeginAt.Before(option.From) {
+			indices = append(indices, index)
+		}
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		return ret[indices[i]].BeginAt.Before(ret[indices[j]].BeginAt)
+	})
+
+	count := 0
+	offset := option.Offset
+	var lastEndAt time.Time
+
+	for _, index := range indices {
+		task := ret[index]
+
+		if 0 < count {
+			if gap := task.BeginAt.Sub(lastEndAt); 0 < gap {
+				if option.Compress && gap < offset {
+					offset -= gap
+
+				} else if option.Compress || option.Mode == ShiftChain {
+					break
+				}
+			}
+		}
+
+		if count == 0 || lastEndAt.Before(task.EndAt) {
+			lastEndAt = task.EndAt
+		}
+
+		ret[index].BeginAt = task.BeginAt.Add(offset)
+		ret[index].EndAt = task.EndAt.Add(offset)
+		count++
+	}
+
+	return ret, count, nil
+}
@@ -0,0 +1,176 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func shiftAt(hour int, minute int) time.Time {
+	return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func shiftTask(subject string, beginHour int, beginMinute int, endHour int, endMinute int) Task {
+	return Task{Subject: subject, BeginAt: shiftAt(beginHour, beginMinute), EndAt: shiftAt(endHour, endMinute)}
+}
+
+func shiftedTasks(tasks []Task, offsets map[string]time.Duration) []Task {
+	ret := []Task{}
+
+	for _, task := range tasks {
+		task.BeginAt = task.BeginAt.Add(offsets[task.Subject])
+		task.EndAt = task.EndAt.Add(offsets[task.Subject])
+		ret = append(ret, task)
+	}
+
+	return ret
+}
+
+func TestShiftTasks(t *testing.T) {
+	gapped := []Task{
+		shiftTask("A", 9, 0, 10, 0),
+		shiftTask("B", 10, 0, 10, 30),
+		shiftTask("C", 10, 40, 11, 0),
+		shiftTask("D", 11, 30, 12, 0),
+	}
+
+	overlapping := []Task{
+		shiftTask("A", 9, 0, 11, 0),
+		shiftTask("B", 9, 30, 10, 0),
+		shiftTask("C", 11, 0, 12, 0),
+		shiftTask("D", 12, 30, 13, 0),
+	}
+
+	unsorted := []Task{
+		shiftTask("C", 10, 40, 11, 0),
+		shiftTask("A", 9, 0, 10, 0),
+		shiftTask("B", 10, 0, 10, 30),
+	}
+
+	cases := []struct {
+		name    string
+		tasks   []Task
+		option  ShiftOption
+		offsets map[string]time.Duration
+		count   int
+	}{
+		{
+			"all moves every task",
+			gapped,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 0), Offset: time.Minute * 15},
+			map[string]time.Duration{"A": time.Minute * 15, "B": time.Minute * 15, "C": time.Minute * 15, "D": time.Minute * 15},
+			4,
+		},
+		{
+			"chain ends at the first gap",
+			gapped,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(9, 0), Offset: time.Minute * 15},
+			map[string]time.Duration{"A": time.Minute * 15, "B": time.Minute * 15},
+			2,
+		},
+		{
+			"compress absorbs shorter gaps and ends at a longer one",
+			gapped,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 0), Offset: time.Minute * 15, Compress: true},
+			map[string]time.Duration{"A": time.Minute * 15, "B": time.Minute * 15, "C": time.Minute * 5},
+			3,
+		},
+		{
+			"chain with compress behaves like all with compress",
+			gapped,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(9, 0), Offset: time.Minute * 15, Compress: true},
+			map[string]time.Duration{"A": time.Minute * 15, "B": time.Minute * 15, "C": time.Minute * 5},
+			3,
+		},
+		{
+			"compress ends at a gap as long as the delay",
+			gapped,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 0), Offset: time.Minute * 10, Compress: true},
+			map[string]time.Duration{"A": time.Minute * 10, "B": time.Minute * 10},
+			2,
+		},
+		{
+			"all with a negative offset",
+			gapped,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 0), Offset: -time.Minute * 15},
+			map[string]time.Duration{"A": -time.Minute * 15, "B": -time.Minute * 15, "C": -time.Minute * 15, "D": -time.Minute * 15},
+			4,
+		},
+		{
+			"chain with a negative offset",
+			gapped,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(9, 0), Offset: -time.Minute * 15},
+			map[string]time.Duration{"A": -time.Minute * 15, "B": -time.Minute * 15},
+			2,
+		},
+		{
+			"overlapping tasks continue the chain from the latest end",
+			overlapping,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(9, 0), Offset: time.Minute * 10},
+			map[string]time.Duration{"A": time.Minute * 10, "B": time.Minute * 10, "C": time.Minute * 10},
+			3,
+		},
+		{
+			"overlapping tasks are not compressed",
+			overlapping,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 0), Offset: time.Minute * 40, Compress: true},
+			map[string]time.Duration{"A": time.Minute * 40, "B": time.Minute * 40, "C": time.Minute * 40, "D": time.Minute * 10},
+			4,
+		},
+		{
+			"a task beginning at from is moved and earlier ones are not",
+			gapped,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(10, 0), Offset: time.Minute * 15},
+			map[string]time.Duration{"B": time.Minute * 15},
+			1,
+		},
+		{
+			"a task spanning from is not moved",
+			overlapping,
+			ShiftOption{Mode: ShiftAll, From: shiftAt(9, 15), Offset: time.Minute * 15},
+			map[string]time.Duration{"B": time.Minute * 15, "C": time.Minute * 15, "D": time.Minute * 15},
+			3,
+		},
+		{
+			"tasks are chained by time and keep their order",
+			unsorted,
+			ShiftOption{Mode: ShiftChain, From: shiftAt(9, 0), Offset: time.Minute * 5},
+			map[string]time.Duration{"A": time.Minute * 5, "B": time.Minute * 5},
+			2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if shifted, count, err := ShiftTasks(c.tasks, c.option); err != nil {
+				t.Fatal(err)
+
+			} else if expected := shiftedTasks(c.tasks, c.offsets); !reflect.DeepEqual(shifted, expected) {
+				t.Errorf("shifted %v, expected %v", shifted, expected)
+
+			} else if count != c.count {
+				t.Errorf("shifted %d tasks, expected %d", count, c.count)
+			}
+		})
+	}
+}
+
+func TestShiftTasksRejectsInvalidOptions(t *testing.T) {
+	tasks := []Task{shiftTask("A", 9, 0, 10, 0)}
+
+	cases := []struct {
+		name   string
+		option ShiftOption
+	}{
+		{"zero offset", ShiftOption{Mode: ShiftAll}},
+		{"compress with a negative offset", ShiftOption{Mode: ShiftAll, Offset: -time.Minute, Compress: true}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := ShiftTasks(tasks, c.option); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
@@ -1,16 +1,16 @@
 package logic
 
 import (
-	"bytes"
-	"encoding/json"
 	"os"
 	"time"
 )
 
 type Task struct {
+	ID      string    `json:"id,omitempty"`
 	Subject string    `json:"subject"`
 	BeginAt time.Time `json:"begin_at"`
 	EndAt   time.Time `json:"end_at"`
+	Source  string    `json:"-"`
 }
 
 func (t *Task) OverlapWith(beginAt time.Time, endAt time.Time) bool {
@@ -22,33 +22,24 @@ func (t *Task) OverlapWith(beginAt time.Time, endAt time.Time) bool {
 }
 
 func LoadTasksFromFile(filename string) ([]Task, error) {
-	ret := []Task{}
-
-	if jsonBytes, err := os.ReadFile(filename); err != nil {
-		return nil, err
-
-	} else if err := json.NewDecoder(bytes.NewReader(jsonBytes)).Decode(&ret); err != nil {
+	if schedule, err := LoadScheduleFromFile(filename); err != nil {
 		return nil, err
 
 	} else {
-		return ret, nil
+		return schedule.Tasks, nil
 	}
 }
 
 func SaveTasksFromFile(filename string, tasks []Task) error {
-	jsonBuffer := bytes.NewBuffer(nil)
-
-	encoder := json.NewEncoder(jsonBuffer)
-	encoder.SetIndent("", "\t")
-	encoder.SetEscapeHTML(false)
+	schedule, err := LoadScheduleFromFile(filename)
+	if os.IsNotExist(err) {
+		schedule = NewSchedule(nil)
 
-	if err := encoder.Encode(tasks); err != nil {
+	} else if err != nil {
 		return err
+	}
 
-	} else if err := os.WriteFile(filename, jsonBuffer.Bytes(), os.ModePerm); err != nil {
-		return err
+	schedule.Tasks = tasks
 
-	} else {
-		return nil
-	}
+	return SaveScheduleToFile(filename, schedule)
 }
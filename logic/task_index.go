@@ -0,0 +1,116 @@
+package logic
+
+import (
+	"sort"
+	"time"
+)
+
+type TaskIndex struct {
+	tasks  []Task
+	maxEnd []time.Time
+}
+
+func NewTaskIndex(tasks []Task) *TaskIndex {
+	ret := new(TaskIndex)
+	ret.tasks = append([]Task{}, tasks...)
+	ret.maxEnd = make([]time.Time, len(tasks))
+
+	sort.SliceStable(ret.tasks, func(i, j int) bool {
+		return ret.tasks[i].BeginAt.Before(ret.tasks[j].BeginAt)
+	})
+
+	ret.build(0, len(ret.tasks))
+
+	return ret
+}
+
+func (ti *TaskIndex) Len() int {
+	if ti == nil {
+		return 0
+	}
+
+	return len(ti.tasks)
+}
+
+func (ti *TaskIndex) Tasks() []Task {
+	if ti == nil {
+		return []Task{}
+	}
+
+	return append([]Task{}, ti.tasks...)
+}
+
+func (ti *TaskIndex) At(at time.Time) []Task {
+	return ti.Between(at, at)
+}
+
+func (ti *TaskIndex) Between(beginAt time.Time, endAt time.Time) []Task {
+	ret := []Task{}
+
+	if ti != nil {
+		ti.collect(0, len(ti.tasks), beginAt, endAt, &ret)
+	}
+
+	return ret
+}
+
+func (ti *TaskIndex) Next(at time.Time) (Task, bool) {
+	if ti == nil {
+		return Task{}, false
+	}
+
+	index := sort.Search(len(ti.tasks), func(i int) bool {
+		return at.Before(ti.tasks[i].BeginAt)
+	})
+
+	if index == len(ti.tasks) {
+		return Task{}, false
+	}
+
+	return ti.tasks[index], true
+}
+
+func (ti *TaskIndex) build(lo int, hi int) time.Time {
+	if hi <= lo {
+		return time.Time{}
+	}
+
+	mid := (lo + hi) / 2
+	maxEnd := ti.tasks[mid].EndAt
+
+	if left := ti.build(lo, mid); maxEnd.Before(left) {
+		maxEnd = left
+	}
+
+	if right := ti.build(mid+1, hi); maxEnd.Before(right) {
+		maxEnd = right
+	}
+
+	ti.maxEnd[mid] = maxEnd
+
+	return maxEnd
+}
+
+func (ti *TaskIndex) collect(lo int, hi int, beginAt time.Time, endAt time.Time, ret *[]Task) {
+	if hi <= lo {
+		return
+	}
+
+	mid := (lo + hi) / 2
+
+	if !beginAt.Before(ti.maxEnd[mid]) {
+		return
+	}
+
+	ti.collect(lo, mid, beginAt, endAt, ret)
+
+	if !ti.tasks[mid].BeginAt.Before(endAt) {
+		return
+	}
+
+	if ti.tasks[mid].OverlapWith(beginAt, endAt) {
+		*ret = append(*ret, ti.tasks[mid])
+	}
+
+	ti.collect(mid+1, hi, beginAt, endAt, ret)
+}
@@ -0,0 +1,155 @@
+package logic
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var indexBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func randomTasks(random *rand.Rand, count int, span time.Duration) []Task {
+	ret := []Task{}
+
+	for index := 0; index < count; index++ {
+		beginAt := indexBaseTime.Add(time.Duration(random.Int63n(int64(span/time.Minute))) * time.Minute)
+		length := time.Duration(random.Intn(240)) * time.Minute
+
+		ret = append(ret, Task{Subject: fmt.Sprint(index), BeginAt: beginAt, EndAt: beginAt.Add(length)})
+	}
+
+	return ret
+}
+
+func linearBetween(tasks []Task, beginAt time.Time, endAt time.Time) []Task {
+	ret := []Task{}
+
+	for _, task := range tasks {
+		if task.OverlapWith(beginAt, endAt) {
+			ret = append(ret, task)
+		}
+	}
+
+	return ret
+}
+
+func linearNext(tasks []Task, at time.Time) (Task, bool) {
+	var ret Task
+	found := false
+
+	for _, task := range tasks {
+		if at.Before(task.BeginAt) && (!found || task.BeginAt.Before(ret.BeginAt)) {
+			ret = task
+			found = true
+		}
+	}
+
+	return ret, found
+}
+
+func subjectsOf(tasks []Task) []string {
+	ret := []string{}
+
+	for _, task := range tasks {
+		ret = append(ret, task.Subject)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func TestTaskIndexMatchesLinearScan(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, count := range []int{0, 1, 2, 7, 100, 1000} {
+		tasks := randomTasks(random, count, time.Hour*24*3)
+		index := NewTaskIndex(tasks)
+
+		if index.Len() != count {
+			t.Fatalf("%d tasks: indexed %d", count, index.Len())
+		}
+
+		for trial := 0; trial < 200; trial++ {
+			beginAt := indexBaseTime.Add(time.Duration(random.Intn(60*24*4)-60*12) * time.Minute)
+			endAt := beginAt.Add(time.Duration(random.Intn(60*6)) * time.Minute)
+
+			if actual, expected := subjectsOf(index.Between(beginAt, endAt)), subjectsOf(linearBetween(tasks, beginAt, endAt)); !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("%d tasks: Between(%v, %v) returned %v, expected %v", count, beginAt, endAt, actual, expected)
+			}
+
+			if actual, expected := subjectsOf(index.At(beginAt)), subjectsOf(linearBetween(tasks, beginAt, beginAt)); !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("%d tasks: At(%v) returned %v, expected %v", count, beginAt, actual, expected)
+			}
+
+			actual, actualFound := index.Next(beginAt)
+			expected, expectedFound := linearNext(tasks, beginAt)
+			if actualFound != expectedFound || !actual.BeginAt.Equal(expected.BeginAt) {
+				t.Fatalf("%d tasks: Next(%v) returned %v, %v, expected %v, %v", count, beginAt, actual, actualFound, expected, expectedFound)
+			}
+		}
+	}
+}
+
+func TestTaskIndexReturnsTasksInBeginOrder(t *testing.T) {
+	tasks := randomTasks(rand.New(rand.NewSource(2)), 500, time.Hour*24)
+	found := NewTaskIndex(tasks).Between(indexBaseTime, indexBaseTime.Add(time.Hour*24))
+
+	if !sort.SliceIsSorted(found, func(i, j int) bool { return found[i].BeginAt.Before(found[j].BeginAt) }) {
+		t.Error("tasks are not sorted by begin time")
+	}
+}
+
+func TestNilTaskIndex(t *testing.T) {
+	var index *TaskIndex
+
+	if _, ok := index.Next(indexBaseTime); ok {
+		t.Error("a nil index has a next task")
+	}
+
+	if index.Len() != 0 || len(index.Tasks()) != 0 || len(index.At(indexBaseTime)) != 0 {
+		t.Error("a nil index is not empty")
+	}
+}
+
+const BENCHMARK_TASKS = 100000
+
+func benchmarkWindows(random *rand.Rand) [][2]time.Time {
+	ret := [][2]time.Time{}
+
+	for index := 0; index < 1000; index++ {
+		beginAt := indexBaseTime.Add(time.Duration(random.Intn(60*24*365)) * time.Minute)
+		ret = append(ret, [2]time.Time{beginAt, beginAt.Add(time.Hour * 4)})
+	}
+
+	return ret
+}
+
+func BenchmarkTaskIndex(b *testing.B) {
+	random := rand.New(rand.NewSource(3))
+	index := NewTaskIndex(randomTasks(random, BENCHMARK_TASKS, time.Hour*24*365))
+	windows := benchmarkWindows(random)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		window := windows[n%len(windows)]
+		index.Between(window[0], window[1])
+	}
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+	random := rand.New(rand.NewSource(3))
+	tasks := randomTasks(random, BENCHMARK_TASKS, time.Hour*24*365)
+	windows := benchmarkWindows(random)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		window := windows[n%len(windows)]
+		linearBetween(tasks, window[0], window[1])
+	}
+}
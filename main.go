@@ -1,45 +1,78 @@
 package main
 
 import (
-	"bytes"
-	_ "embed"
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 	"time-meter/logic"
 	"time-meter/setting"
+	"time-meter/store"
 	"time-meter/textmap"
 	"time-meter/ui"
+	"time-meter/watcher"
 	"time-meter/webapi"
 )
 
-const SCHEDULE_FILENAME = "schedule.json"
 const SETTINGS_FILENAME = "settings.json"
 
-//go:embed embed/text.json
-var embedTextJson []byte
-
-var textMap = textmap.New()
+var textMap = textmap.New(DEFAULT_LOCALE)
 var settings = new(setting.Settings)
 var webApi = webapi.New()
 var uiController = ui.NewController()
-var fileWatcher = new(FileWatcher)
+var taskStoreUpdateMutex sync.Mutex
+var taskStoreMutex sync.Mutex
+var taskStore store.TaskStore
+var taskStoreSettings setting.Settings
+var stopTaskStore context.CancelFunc
+var settingsWatcher watcher.Watcher
+var watchContext, stopWatching = context.WithCancel(context.Background())
+var serverMutex sync.Mutex
+var server *http.Server
+var serverPort int
+var errorMessageMutex sync.Mutex
+var scheduleErrorMessage string
+var settingsErrorMessage string
+var textsErrorMessage string
+var textsWarningMessage string
+var settingsSources []setting.Source
+var settingsMutex sync.Mutex
+var appliedSettings setting.Settings
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		println(err.Error())
 		os.Exit(1)
 	}
 }
 
-func run() error {
+func run(args []string) error {
+	flagSource, commandArgs, err := setting.FlagSource(args)
+	if err != nil {
+		return err
+	}
+
+	settingsSources = append(defaultSettingsSources(), flagSource)
+
+	if 0 < len(commandArgs) {
+		return runCommand(commandArgs)
+	}
+
 	settings.Default()
-	if err := settings.LoadFile(SETTINGS_FILENAME); err != nil {
-		println(err.Error())
+	settingsErr := settings.LoadLayers(settingsSources...)
+
+	if err := loadEmbedTexts(textMap); err != nil {
+		return err
 	}
 
+	textsWarnings, textsErr := loadUserTexts(textMap)
+
+	textMap.SetLocale(detectLocale(settings.Language))
 	uiController.SetTextMap(textMap)
 	uiController.SetSettings(settings)
 
@@ -48,41 +81,72 @@ func run() error {
 	}
 	defer finalize()
 
-	fileWatcher.filename = SCHEDULE_FILENAME
-	fileWatcher.onFileChanged = func() {
-		reloadSchedule()
-	}
+	setSettingsError(settingsErr)
+	setTextsError(textsErr)
+	setTextsWarnings(textsWarnings)
+
+	appliedSettings = *settings
+	publishSelections(appliedSettings)
+	webApi.SetFreeBusyOption(freeBusyOptionOf(appliedSettings))
+
+	settingsWatcher = newWatcher(*settings, settingsPatterns())
+	settingsWatcher.OnChanged(func(filename string) {
+		reloadSettings()
+	})
+	settingsWatcher.OnError(func(err error) {
+		println(err.Error())
+	})
 
 	webApi.OnHandled(func(t webapi.RequestType) {
 		switch t {
-		case webapi.PostSchedule:
-			if err := logic.SaveTasksFromFile(SCHEDULE_FILENAME, webApi.PostedTasks()); err != nil {
+		case webapi.PostTheme:
+			if err := switchTheme(webApi.PostedTheme()); err != nil {
+				println(err.Error())
+			}
+
+		case webapi.PostProfile:
+			if err := switchProfile(webApi.PostedProfile()); err != nil {
 				println(err.Error())
 			}
 		}
 	})
 
-	fileWatcher.Watch()
-
-	if settings.ServerEnabled {
-		mux := http.NewServeMux()
-		mux.Handle("/api", http.StripPrefix("/api", webApi))
-		go http.ListenAndServe(fmt.Sprintf(":%d", settings.Port), mux)
+	if err := settingsWatcher.Watch(watchContext, settingsPatterns()...); err != nil {
+		return err
 	}
 
+	updateServer(settings.ServerEnabled, settings.Port)
+	defer updateServer(false, 0)
+
+	updateTaskStore(*settings)
 	reloadSchedule()
 
 	uiController.OnPopupMenuCommand(func(menuId ui.MenuId) {
-		switch menuId {
-		case ui.MID_EDIT_SCHEDULE:
-			if err := handleEditSchedule(); err != nil {
-				uiController.ShowErrorMessageBox(
-					textMap.Of("NOTIFY_FAILED_OPERATION").
-						Set("detail", err.Error()).
-						String())
-			}
+		switch {
+		case menuId == ui.MID_EDIT_SCHEDULE:
+			notifyIfFailed(handleEditSchedule())
+
+		case menuId == ui.MID_EDIT_SETTINGS:
+			notifyIfFailed(handleEditSettings())
 
-		case ui.MID_QUIT:
+		case menuId == ui.MID_SHIFT_5_MINUTES:
+			notifyIfFailed(handleShiftSchedule(5 * time.Minute))
+
+		case menuId == ui.MID_SHIFT_10_MINUTES:
+			notifyIfFailed(handleShiftSchedule(10 * time.Minute))
+
+		case menuId == ui.MID_SHIFT_15_MINUTES:
+			notifyIfFailed(handleShiftSchedule(15 * time.Minute))
+
+		case ui.MID_THEME_FIRST <= menuId && menuId <= ui.MID_THEME_LAST:
+			current := appliedSettingsSnapshot()
+			notifyIfFailed(switchTheme(nameOfMenu(current.ThemeNames(), menuId-ui.MID_THEME_FIRST)))
+
+		case ui.MID_PROFILE_FIRST <= menuId && menuId <= ui.MID_PROFILE_LAST:
+			current := appliedSettingsSnapshot()
+			notifyIfFailed(switchProfile(nameOfMenu(current.ProfileNames(), menuId-ui.MID_PROFILE_FIRST)))
+
+		case menuId == ui.MID_QUIT:
 			uiController.Quit()
 		}
 	})
@@ -93,41 +157,104 @@ func run() error {
 }
 
 func initialize() error {
-	if err := textMap.LoadJson(bytes.NewReader(embedTextJson)); err != nil {
+	if err := uiController.Initialize(); err != nil {
 		return err
 	}
 
-	if err := fileWatcher.Initialize(); err != nil {
+	return nil
+}
+
+func finalize() {
+	uiController.Finalize()
+	stopWatching()
+	settingsWatcher.Wait()
+
+	taskStoreUpdateMutex.Lock()
+	defer taskStoreUpdateMutex.Unlock()
+
+	taskStoreMutex.Lock()
+	oldStore := taskStore
+	taskStore = nil
+	taskStoreMutex.Unlock()
+
+	if oldStore != nil {
+		oldStore.Wait()
+		oldStore.Close()
+	}
+}
+
+func notifyIfFailed(err error) {
+	if err != nil {
+		uiController.ShowErrorMessageBox(
+			textMap.Of("NOTIFY_FAILED_OPERATION").
+				Set("detail", err.Error()).
+				String())
+	}
+}
+
+func handleEditSchedule() error {
+	current := appliedSettingsSnapshot()
+	if current.TaskStore != setting.TASK_STORE_JSON {
+		return fmt.Errorf(`the "%s" task store cannot be edited as a file`, current.TaskStore)
+	}
+
+	return openWithEditor(currentTaskStore().Location(), saveTemplateTasks)
+}
+
+func handleShiftSchedule(offset time.Duration) error {
+	currentStore := currentTaskStore()
+	if currentStore == nil {
+		return nil
+	}
+
+	tasks, err := currentStore.Load()
+	if err != nil {
 		return err
 	}
 
-	if err := uiController.Initialize(); err != nil {
+	shiftedTasks, count, err := logic.ShiftTasks(tasks, logic.ShiftOption{
+		Mode:     logic.ShiftChain,
+		From:     time.Now(),
+		Offset:   offset,
+		Compress: true,
+	})
+	if err != nil || count == 0 {
 		return err
 	}
 
-	return nil
+	return currentStore.Save(shiftedTasks)
 }
 
-func finalize() {
-	uiController.Finalize()
-	fileWatcher.Finalize()
+func handleEditSettings() error {
+	return openWithEditor(SETTINGS_FILENAME, saveWorkspaceSettings)
 }
 
-func handleEditSchedule() error {
-	if fileInfo, err := os.Stat(SCHEDULE_FILENAME); err != nil {
+func saveWorkspaceSettings(filename string) error {
+	var workspace setting.Settings
+	workspace.Default()
+
+	if err := workspace.LoadLayers(setting.FileSource("workspace", filename)); err != nil {
+		return err
+	}
+
+	return workspace.SaveFile(filename)
+}
+
+func openWithEditor(filename string, createFile func(filename string) error) error {
+	if fileInfo, err := os.Stat(filename); err != nil {
 		if !os.IsNotExist(err) {
 			return err
 		}
 
-		if err := saveTemplateTasks(SCHEDULE_FILENAME); err != nil {
+		if err := createFile(filename); err != nil {
 			return err
 		}
 
 	} else if fileInfo.IsDir() {
-		return fmt.Errorf(`"%s" is a directory`, SCHEDULE_FILENAME)
+		return fmt.Errorf(`"%s" is a directory`, filename)
 	}
 
-	cmd := exec.Command(settings.ScheduleEditCommand, SCHEDULE_FILENAME)
+	cmd := exec.Command(settings.ScheduleEditCommand, filename)
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -136,17 +263,321 @@ func handleEditSchedule() error {
 }
 
 func reloadSchedule() {
-	loadedTasks, err := logic.LoadTasksFromFile(SCHEDULE_FILENAME)
+	currentStore := currentTaskStore()
+	if currentStore == nil {
+		return
+	}
+
+	loadedTasks, err := currentStore.Load()
+
+	uiController.SetTasks(loadedTasks)
+
+	messages := []string{}
+
+	for _, failure := range store.FailuresOf(err, currentStore.Location()) {
+		println(fmt.Sprintf("%s: %s", failure.Filename, failure.Err.Error()))
+
+		messages = append(messages, textMap.Of("NOTIFY_FAILED_SCHEDULE").
+			Set("filename", failure.Filename).
+			String())
+	}
+
+	setScheduleErrorMessage(strings.Join(messages, "\n"))
+}
+
+func currentTaskStore() store.TaskStore {
+	taskStoreMutex.Lock()
+	defer taskStoreMutex.Unlock()
+
+	return taskStore
+}
+
+func updateTaskStore(s setting.Settings) bool {
+	taskStoreUpdateMutex.Lock()
+	defer taskStoreUpdateMutex.Unlock()
+
+	taskStoreMutex.Lock()
+	unchanged := taskStore != nil &&
+		taskStoreSettings.TaskStore == s.TaskStore &&
+		taskStoreSettings.SqliteFile == s.SqliteFile &&
+		reflect.DeepEqual(taskStoreSettings.ScheduleFiles, s.ScheduleFiles) &&
+		taskStoreSettings.FileWatcher == s.FileWatcher &&
+		taskStoreSettings.PollingInterval == s.PollingInterval
+	taskStoreMutex.Unlock()
+
+	if unchanged {
+		return false
+	}
+
+	newStore, err := newTaskStore(s)
 	if err != nil {
-		uiController.SetErrorMessage(textMap.Of("NOTIFY_FAILED_SCHEDULE").
-			Set("filename", SCHEDULE_FILENAME).
+		println(err.Error())
+		setScheduleErrorMessage(textMap.Of("NOTIFY_FAILED_SCHEDULE").
+			Set("filename", s.SqliteFile).
 			String())
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(watchContext)
+
+	newStore.OnChanged(func() {
+		reloadSchedule()
+	})
+	newStore.OnError(func(err error) {
+		println(err.Error())
+	})
+
+	taskStoreMutex.Lock()
+	oldStore := taskStore
+	stopOldStore := stopTaskStore
+	taskStore = newStore
+	taskStoreSettings = s
+	stopTaskStore = cancel
+	taskStoreMutex.Unlock()
+
+	if oldStore != nil {
+		stopOldStore()
+		oldStore.Wait()
+		oldStore.Close()
+	}
+
+	if err := newStore.Watch(ctx); err != nil {
+		println(err.Error())
+	}
+
+	webApi.SetStore(newStore)
+
+	return true
+}
+
+func newTaskStore(s setting.Settings) (store.TaskStore, error) {
+	switch s.TaskStore {
+	case setting.TASK_STORE_SQLITE:
+		return store.NewSqlite(s.SqliteFile, s.PollingInterval)
+
+	default:
+		return store.NewJsonFile(s.ScheduleFiles, func(patterns []string) watcher.Watcher {
+			return newWatcher(s, patterns)
+		}), nil
+	}
+}
+
+func newWatcher(s setting.Settings, patterns []string) watcher.Watcher {
+	switch s.FileWatcher {
+	case setting.FILE_WATCHER_FSNOTIFY:
+		return watcher.New(watcher.DEFAULT_DEBOUNCE)
+
+	case setting.FILE_WATCHER_POLLING:
+		return watcher.NewPolling(s.PollingInterval)
+
+	default:
+		return watcher.NewAuto(watcher.DEFAULT_DEBOUNCE, s.PollingInterval, patterns...)
+	}
+}
+
+func reloadSettings() {
+	textsWarnings, textsErr := loadUserTexts(textMap)
+	setTextsError(textsErr)
+	setTextsWarnings(textsWarnings)
+
+	var loadedSettings setting.Settings
+	loadedSettings.Default()
+
+	if err := loadedSettings.LoadLayers(settingsSources...); err != nil {
+		setSettingsError(err)
 		return
 	}
 
-	uiController.SetTasks(loadedTasks)
+	setSettingsError(nil)
+
+	applySettings(loadedSettings)
+}
+
+func applySettings(newSettings setting.Settings) {
+	settingsMutex.Lock()
+	appliedSettings = newSettings
+	settingsMutex.Unlock()
+
+	textMap.SetLocale(detectLocale(newSettings.Language))
+	uiController.UpdateSettings(newSettings)
+
+	if updateTaskStore(newSettings) {
+		reloadSchedule()
+	}
+
+	publishSelections(newSettings)
+	webApi.SetFreeBusyOption(freeBusyOptionOf(newSettings))
+	updateServer(newSettings.ServerEnabled, newSettings.Port)
+}
+
+func appliedSettingsSnapshot() setting.Settings {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	return appliedSettings
+}
+
+func freeBusyOptionOf(s setting.Settings) logic.FreeBusyOption {
+	var ret logic.FreeBusyOption
+	ret.WorkdayBeginAt = s.WorkingHoursBegin
+	ret.WorkdayEndAt = s.WorkingHoursEnd
+	ret.Workdays = s.WorkingDays
+	ret.Buffer = s.SlotBuffer
+	ret.MinimumSlot = s.MinimumSlot
+
+	for _, r := range s.Breaks {
+		ret.Breaks = append(ret.Breaks, logic.ClockRange{BeginAt: r.Begin, EndAt: r.End})
+	}
 
-	uiController.SetErrorMessage("")
+	return ret
+}
+
+func publishSelections(s setting.Settings) {
+	webApi.SetThemes(logic.Selection{Active: s.Theme, Available: s.ThemeNames()})
+	webApi.SetProfiles(logic.Selection{Active: s.Profile, Available: s.ProfileNames()})
+}
+
+func switchTheme(name string) error {
+	current := appliedSettingsSnapshot()
+
+	switched, err := current.SwitchTheme(name)
+	if err != nil {
+		return err
+	}
+
+	applySettings(switched)
+
+	return nil
+}
+
+func switchProfile(name string) error {
+	current := appliedSettingsSnapshot()
+
+	switched, err := current.SwitchProfile(name)
+	if err != nil {
+		return err
+	}
+
+	applySettings(switched)
+
+	return nil
+}
+
+func nameOfMenu(names []string, offset ui.MenuId) string {
+	if index := int(offset) - 1; 0 <= index && index < len(names) {
+		return names[index]
+	}
+
+	return ""
+}
+
+func setSettingsError(err error) {
+	message := ""
+
+	if err != nil {
+		println(err.Error())
+
+		if !os.IsNotExist(err) {
+			message = textMap.Of("NOTIFY_INVALID_SETTINGS").
+				Set("detail", err.Error()).
+				String()
+		}
+	}
+
+	errorMessageMutex.Lock()
+	settingsErrorMessage = message
+	errorMessageMutex.Unlock()
+
+	updateErrorMessage()
+}
+
+func setTextsError(err error) {
+	message := ""
+
+	if err != nil {
+		println(err.Error())
+
+		message = textMap.Of("NOTIFY_INVALID_TEXTS").
+			Set("detail", err.Error()).
+			String()
+	}
+
+	errorMessageMutex.Lock()
+	textsErrorMessage = message
+	errorMessageMutex.Unlock()
+
+	updateErrorMessage()
+}
+
+func setTextsWarnings(warnings []string) {
+	message := ""
+
+	if 0 < len(warnings) {
+		detail := strings.Join(warnings, "\n")
+		println(detail)
+
+		message = textMap.Of("NOTIFY_IGNORED_TEXTS").
+			Set("detail", detail).
+			String()
+	}
+
+	errorMessageMutex.Lock()
+	textsWarningMessage = message
+	errorMessageMutex.Unlock()
+
+	updateErrorMessage()
+}
+
+func setScheduleErrorMessage(message string) {
+	errorMessageMutex.Lock()
+	scheduleErrorMessage = message
+	errorMessageMutex.Unlock()
+
+	updateErrorMessage()
+}
+
+func updateErrorMessage() {
+	errorMessageMutex.Lock()
+	defer errorMessageMutex.Unlock()
+
+	messages := []string{}
+
+	for _, message := range []string{settingsErrorMessage, textsErrorMessage, textsWarningMessage, scheduleErrorMessage} {
+		if message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	uiController.SetErrorMessage(strings.Join(messages, "\n\n"))
+}
+
+func updateServer(enabled bool, port int) {
+	serverMutex.Lock()
+	defer serverMutex.Unlock()
+
+	if server != nil && (!enabled || port != serverPort) {
+		if err := server.Shutdown(context.Background()); err != nil {
+			println(err.Error())
+		}
+
+		server = nil
+	}
+
+	if !enabled || server != nil {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", webApi))
+
+	server = &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+	serverPort = port
+
+	go func(server *http.Server) {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			println(err.Error())
+		}
+	}(server)
 }
 
 func saveTemplateTasks(filename string) error {
@@ -154,5 +585,12 @@ func saveTemplateTasks(filename string) error {
 	task.Subject = textMap.Of("NOUN_SAMPLE_TASK").String()
 	task.BeginAt = time.Now().Truncate(time.Minute).Add(time.Minute * 3)
 	task.EndAt = task.BeginAt.Add(time.Hour)
-	return logic.SaveTasksFromFile(filename, []logic.Task{task})
+
+	schedule := logic.NewSchedule([]logic.Task{task})
+
+	if current := appliedSettingsSnapshot(); current.ServerEnabled {
+		schedule.Schema = fmt.Sprintf("http://localhost:%d/api/schema/schedule.json", current.Port)
+	}
+
+	return logic.SaveScheduleToFile(filename, schedule)
 }
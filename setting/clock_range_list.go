@@ -0,0 +1,68 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"time-meter/jsonschema"
+)
+
+type ClockRange struct {
+	Begin time.Duration
+	End   time.Duration
+}
+
+type clockRangeList []ClockRange
+
+func (crl *clockRangeList) MarshalJSON() ([]byte, error) {
+	ranges := []string{}
+
+	for _, r := range *crl {
+		ranges = append(ranges, formatClock(r.Begin)+"-"+formatClock(r.End))
+	}
+
+	return json.Marshal(ranges)
+}
+
+func (crl *clockRangeList) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "array",
+		"description": "times of day excluded from working hours",
+		"items":       jsonschema.Schema{"type": "string", "pattern": `^\d{1,2}:\d{2}-\d{1,2}:\d{2}$`},
+		"examples":    [][]string{{"12:00-13:00"}},
+	}
+}
+
+func (crl *clockRangeList) UnmarshalJSON(data []byte) error {
+	var ranges []string
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return err
+	}
+
+	ret := clockRangeList{}
+
+	for _, str := range ranges {
+		beginStr, endStr, ok := strings.Cut(str, "-")
+		if !ok {
+			return fmt.Errorf(`invalid time range "%s", expected such as "12:00-13:00"`, str)
+		}
+
+		var begin, end clockString
+
+		if err := begin.UnmarshalJSON([]byte(fmt.Sprintf("%q", strings.TrimSpace(beginStr)))); err != nil {
+			return err
+
+		} else if err := end.UnmarshalJSON([]byte(fmt.Sprintf("%q", strings.TrimSpace(endStr)))); err != nil {
+			return err
+
+		} else if end <= begin {
+			return fmt.Errorf(`invalid time range "%s", the end must be later than the beginning`, str)
+		}
+
+		ret = append(ret, ClockRange{time.Duration(begin), time.Duration(end)})
+	}
+
+	*crl = ret
+	return nil
+}
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"time-meter/jsonschema"
+)
+
+type clockString time.Duration
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+
+func (cs *clockString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatClock(time.Duration(*cs)))
+}
+
+func (cs *clockString) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "string",
+		"description": "time of day",
+		"pattern":     `^\d{1,2}:\d{2}$`,
+		"examples":    []string{"09:00", "18:30"},
+	}
+}
+
+func (cs *clockString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	matches := clockPattern.FindStringSubmatch(str)
+	if matches == nil {
+		return fmt.Errorf(`invalid time of day "%s", expected such as "09:00"`, str)
+	}
+
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+
+	if 24 < hour || 59 < minute || hour == 24 && minute != 0 {
+		return fmt.Errorf(`invalid time of day "%s", expected between "00:00" and "24:00"`, str)
+	}
+
+	*cs = clockString(time.Hour*time.Duration(hour) + time.Minute*time.Duration(minute))
+	return nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
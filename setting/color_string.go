@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"encoding/json"
+	"time-meter/color"
+	"time-meter/jsonschema"
+)
+
+type colorString color.Color
+
+func (cs *colorString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(color.Color(*cs).String())
+}
+
+func (cs *colorString) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "string",
+		"description": `"#rgb", "#rgba", "#rrggbb", "#rrggbbaa", "rgb()", "hsl()" or a CSS color name`,
+		"examples":    []string{"#ff8000", "#ff800080", "rgb(255 128 0 / 50%)", "orange"},
+	}
+}
+
+func (cs *colorString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	parsed, err := color.Parse(str)
+	if err != nil {
+		return err
+	}
+
+	*cs = colorString(parsed)
+	return nil
+}
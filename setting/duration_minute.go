@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+	"time-meter/jsonschema"
 )
 
 type durationMinute time.Duration
@@ -12,6 +13,14 @@ func (dm *durationMinute) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%d", time.Duration(*dm)/time.Minute)), nil
 }
 
+func (dm *durationMinute) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "integer",
+		"description": "Minutes",
+		"deprecated":  true,
+	}
+}
+
 func (dm *durationMinute) UnmarshalJSON(data []byte) error {
 	var minutes time.Duration
 	if err := json.Unmarshal(data, &minutes); err != nil {
@@ -0,0 +1,98 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"time-meter/jsonschema"
+)
+
+type durationString time.Duration
+
+var isoDurationPattern = regexp.MustCompile(`^(-)?P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+func (ds *durationString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatDuration(time.Duration(*ds)))
+}
+
+func (ds *durationString) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "string",
+		"description": `Go-style or ISO-8601 duration`,
+		"examples":    []string{"90m", "1h30m", "PT2H"},
+	}
+}
+
+func (ds *durationString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	duration, err := parseDuration(str)
+	if err != nil {
+		return err
+	}
+
+	*ds = durationString(duration)
+	return nil
+}
+
+func parseDuration(str string) (time.Duration, error) {
+	if duration, err := time.ParseDuration(str); err == nil {
+		return duration, nil
+	}
+
+	if duration, ok := parseIsoDuration(strings.ToUpper(str)); ok {
+		return duration, nil
+	}
+
+	return 0, fmt.Errorf(`invalid duration "%s", expected such as "90m", "1h30m" or "PT1H30M"`, str)
+}
+
+func parseIsoDuration(str string) (time.Duration, bool) {
+	matches := isoDurationPattern.FindStringSubmatch(str)
+	if matches == nil || str == "P" || str == "-P" || strings.HasSuffix(str, "T") {
+		return 0, false
+	}
+
+	units := []time.Duration{time.Hour * 24 * 7, time.Hour * 24, time.Hour, time.Minute, time.Second}
+
+	var ret time.Duration
+
+	for index, unit := range units {
+		if matches[index+2] == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(matches[index+2], 64)
+		if err != nil {
+			return 0, false
+		}
+
+		ret += time.Duration(value * float64(unit))
+	}
+
+	if matches[1] != "" {
+		ret = -ret
+	}
+
+	return ret, true
+}
+
+func formatDuration(duration time.Duration) string {
+	ret := duration.String()
+
+	if strings.HasSuffix(ret, "m0s") {
+		ret = strings.TrimSuffix(ret, "0s")
+	}
+
+	if strings.HasSuffix(ret, "h0m") {
+		ret = strings.TrimSuffix(ret, "0m")
+	}
+
+	return ret
+}
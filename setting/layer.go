@@ -0,0 +1,301 @@
+package setting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const DEFAULT_SOURCE_NAME = "default"
+
+type Source interface {
+	Name() string
+	load() (nilableSettings, error)
+}
+
+type Origin struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+type fileSource struct {
+	name     string
+	filename string
+}
+
+type valuesSource struct {
+	name   string
+	values map[string]string
+}
+
+func FileSource(name string, filename string) Source {
+	return &fileSource{name, filename}
+}
+
+func EnvSource(prefix string, environ []string) Source {
+	values := make(map[string]string)
+
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		values[strings.ToLower(strings.TrimPrefix(key, prefix))] = value
+	}
+
+	return &valuesSource{"env", values}
+}
+
+func FlagSource(args []string) (Source, []string, error) {
+	values := make(map[string]string)
+	rest := []string{}
+	knownKeys := knownKeysOf()
+
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		key := strings.ReplaceAll(name, "-", "_")
+		if !knownKeys[key] {
+			rest = append(rest, arg)
+			continue
+		}
+
+		if !ok {
+			if len(args) <= index+1 {
+				return nil, nil, fmt.Errorf(`flag "%s" needs a value`, arg)
+			}
+
+			index++
+			value = args[index]
+		}
+
+		values[key] = value
+	}
+
+	return &valuesSource{"flag", values}, rest, nil
+}
+
+func (s *Settings) LoadLayers(sources ...Source) error {
+	nilable, _, err := mergeLayers(sources)
+	if err != nil {
+		return err
+	}
+
+	settings, err := nilable.resolve(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	*s = settings
+
+	return nil
+}
+
+func ExplainLayers(sources ...Source) ([]Origin, error) {
+	nilable, origins, err := mergeLayers(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := nilable.resolve(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile, ok := nilable.Profiles[settings.Profile]; ok {
+		for _, key := range profile.keys() {
+			origins[key] = fmt.Sprintf("%s (profile %s)", origins["profiles"], settings.Profile)
+		}
+	}
+
+	if theme, ok := nilable.Themes[settings.Theme]; ok {
+		for _, key := range theme.keys() {
+			origins[key] = fmt.Sprintf("%s (theme %s)", origins["themes"], settings.Theme)
+		}
+	}
+
+	effective := newNilableSettings(&settings, nil)
+
+	ret := []Origin{}
+
+	value := reflect.ValueOf(&effective).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		if value.Field(index).IsNil() {
+			continue
+		}
+
+		key := jsonKeyOf(value.Type().Field(index))
+
+		source, ok := origins[key]
+		if !ok {
+			source = DEFAULT_SOURCE_NAME
+		}
+
+		if valueJson, err := json.Marshal(value.Field(index).Interface()); err != nil {
+			return nil, err
+
+		} else {
+			ret = append(ret, Origin{key, string(valueJson), source})
+		}
+	}
+
+	return ret, nil
+}
+
+func mergeLayers(sources []Source) (nilableSettings, map[string]string, error) {
+	var ret nilableSettings
+	origins := make(map[string]string)
+
+	var errs ValidationErrors
+
+	for _, source := range sources {
+		layer, err := source.load()
+		if err != nil {
+			var layerErrs ValidationErrors
+			if !errors.As(err, &layerErrs) {
+				return ret, nil, fmt.Errorf("%s: %w", source.Name(), err)
+			}
+
+			for _, layerErr := range layerErrs {
+				layerErr.Source = source.Name()
+				errs = append(errs, layerErr)
+			}
+
+			continue
+		}
+
+		ret.merge(&layer, origins, source.Name())
+	}
+
+	errs = append(errs, ret.validateCombinations()...)
+	errs = append(errs, ret.validateReferences()...)
+
+	if 0 < len(errs) {
+		return ret, nil, errs
+	}
+
+	return ret, origins, nil
+}
+
+func (ns *nilableSettings) merge(other *nilableSettings, origins map[string]string, sourceName string) {
+	value := reflect.ValueOf(ns).Elem()
+	otherValue := reflect.ValueOf(other).Elem()
+
+	for index := 0; index < value.NumField(); index++ {
+		if otherValue.Field(index).IsNil() {
+			continue
+		}
+
+		if otherValue.Field(index).Kind() == reflect.Map {
+			if value.Field(index).IsNil() {
+				value.Field(index).Set(reflect.MakeMap(value.Field(index).Type()))
+			}
+
+			iterator := otherValue.Field(index).MapRange()
+			for iterator.Next() {
+				value.Field(index).SetMapIndex(iterator.Key(), iterator.Value())
+			}
+
+		} else {
+			value.Field(index).Set(otherValue.Field(index))
+		}
+
+		origins[jsonKeyOf(value.Type().Field(index))] = sourceName
+	}
+}
+
+func (fs *fileSource) Name() string {
+	return fs.name
+}
+
+func (fs *fileSource) load() (nilableSettings, error) {
+	var ret nilableSettings
+
+	if jsonBytes, err := os.ReadFile(fs.filename); err != nil {
+		if os.IsNotExist(err) {
+			return ret, nil
+		}
+
+		return ret, err
+
+	} else if err := ret.decode(jsonBytes); err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+func (vs *valuesSource) Name() string {
+	return vs.name
+}
+
+func (vs *valuesSource) load() (nilableSettings, error) {
+	var ret nilableSettings
+
+	knownKeys := knownKeysOf()
+
+	var errs ValidationErrors
+	fields := make(map[string]json.RawMessage)
+
+	for key, value := range vs.values {
+		if !knownKeys[key] {
+			errs = append(errs, ValidationError{Path: jsonPath(key), Reason: "unknown setting"})
+			continue
+		}
+
+		if valueJson, err := json.Marshal(value); err != nil {
+			return ret, err
+
+		} else {
+			fields[key] = valueJson
+		}
+	}
+
+	if err := ret.decodeFields(fields, true); err != nil {
+		var decodeErrs ValidationErrors
+		if !errors.As(err, &decodeErrs) {
+			return ret, err
+		}
+
+		errs = append(errs, decodeErrs...)
+	}
+
+	if 0 < len(errs) {
+		return ret, errs
+	}
+
+	return ret, nil
+}
+
+func (ns *nilableSettings) keys() []string {
+	ret := []string{}
+
+	value := reflect.ValueOf(ns).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		if !value.Field(index).IsNil() {
+			ret = append(ret, jsonKeyOf(value.Type().Field(index)))
+		}
+	}
+
+	return ret
+}
+
+func knownKeysOf() map[string]bool {
+	ret := make(map[string]bool)
+
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(nilableSettings{})) {
+		ret[jsonKeyOf(field)] = true
+	}
+
+	return ret
+}
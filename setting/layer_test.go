@@ -0,0 +1,146 @@
+package setting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeLayer(t *testing.T, dir string, name string, jsonText string) Source {
+	t.Helper()
+
+	filename := filepath.Join(dir, name+".json")
+	if err := os.WriteFile(filename, []byte(jsonText), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	return FileSource(name, filename)
+}
+
+func testLayers(t *testing.T) []Source {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	flagSource, rest, err := FlagSource([]string{"--meter-width", "90", "open"})
+	if err != nil {
+		t.Fatal(err)
+
+	} else if !reflect.DeepEqual(rest, []string{"open"}) {
+		t.Fatalf("rest args are %v", rest)
+	}
+
+	return []Source{
+		writeLayer(t, dir, "system", `{"meter_width": 60, "port": 50001, "working_hours_begin": "19:00"}`),
+		writeLayer(t, dir, "user", `{"meter_width": 70, "working_hours_end": "23:00", "past_duration": "0s"}`),
+		writeLayer(t, dir, "workspace", `{"meter_width": 80, "future_duration": "2h"}`),
+		FileSource("missing", filepath.Join(dir, "missing.json")),
+		EnvSource("TIME_METER_", []string{"TIME_METER_METER_WIDTH=85", "TIME_METER_CHART_VISIBLE=false", "OTHER=1"}),
+		flagSource,
+	}
+}
+
+func TestLoadLayersPrecedence(t *testing.T) {
+	var s Settings
+	if err := s.LoadLayers(testLayers(t)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.MeterWidth != 90 {
+		t.Errorf("meter width is %d, expected the flag value", s.MeterWidth)
+	}
+
+	if s.Port != 50001 || s.ChartVisible {
+		t.Errorf("port is %d and chart visible is %v", s.Port, s.ChartVisible)
+	}
+
+	if s.WorkingHoursBegin != time.Hour*19 || s.WorkingHoursEnd != time.Hour*23 {
+		t.Errorf("working hours are %v-%v", s.WorkingHoursBegin, s.WorkingHoursEnd)
+	}
+
+	if s.PastDuration != 0 || s.FutureDuration != time.Hour*2 {
+		t.Errorf("past and future are %v and %v", s.PastDuration, s.FutureDuration)
+	}
+}
+
+func TestLoadLayersValidatesCombinationsAfterMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		name     string
+		layers   []string
+		expected []string
+	}{
+		{"split working hours", []string{`{"working_hours_begin": "19:00"}`, `{"working_hours_end": "23:00"}`}, nil},
+		{"split past and future", []string{`{"past_duration": "0s"}`, `{"future_duration": "1h"}`}, nil},
+		{"reversed working hours", []string{`{"working_hours_begin": "19:00"}`, `{"working_hours_end": "08:00"}`}, []string{"$.working_hours_end"}},
+		{"empty range", []string{`{"past_duration": "0s"}`, `{"future_duration": "0s"}`}, []string{"$.future_duration"}},
+		{"profile against base", []string{`{"working_hours_end": "23:00"}`, `{"profiles": {"night": {"working_hours_begin": "20:00"}}}`}, nil},
+		{"reversed profile", []string{`{"working_hours_end": "12:00"}`, `{"profiles": {"night": {"working_hours_begin": "20:00"}}}`}, []string{"$.profiles.night.working_hours_end"}},
+		{"reversed break", []string{`{"breaks": ["13:00-12:00"]}`}, []string{"$.breaks"}},
+		{"invalid field", []string{`{"meter_width": 0}`, `{"meter_width": 10}`}, []string{"$.meter_width"}},
+	}
+
+	for _, c := range cases {
+		sources := []Source{}
+		for index, layer := range c.layers {
+			sources = append(sources, writeLayer(t, dir, c.name+string(rune('a'+index)), layer))
+		}
+
+		var s Settings
+		err := s.LoadLayers(sources...)
+
+		paths := []string(nil)
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			for _, e := range errs {
+				paths = append(paths, e.Path)
+			}
+
+		} else if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if !reflect.DeepEqual(paths, c.expected) {
+			t.Errorf("%s: failed at %v, expected %v", c.name, paths, c.expected)
+		}
+	}
+}
+
+func TestExplainLayers(t *testing.T) {
+	origins, err := ExplainLayers(testLayers(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := make(map[string]string)
+	values := make(map[string]string)
+	for _, origin := range origins {
+		sources[origin.Key] = origin.Source
+		values[origin.Key] = origin.Value
+	}
+
+	expected := map[string]string{
+		"meter_width":         "flag",
+		"chart_visible":       "env",
+		"future_duration":     "workspace",
+		"working_hours_end":   "user",
+		"past_duration":       "user",
+		"port":                "system",
+		"working_hours_begin": "system",
+		"meter_opacity":       DEFAULT_SOURCE_NAME,
+	}
+
+	for key, source := range expected {
+		if sources[key] != source {
+			t.Errorf("%s comes from %s, expected %s", key, sources[key], source)
+		}
+	}
+
+	if values["meter_width"] != "90" || values["working_hours_begin"] != `"19:00"` {
+		t.Errorf("values are %v", values)
+	}
+}
@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"encoding/json"
+	"strings"
+	"time-meter/jsonschema"
+)
+
+type pathList []string
+
+func (pl *pathList) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"description": "file, directory or glob pattern, or a list of them",
+		"anyOf": []jsonschema.Schema{
+			{"type": "string"},
+			{"type": "array", "items": jsonschema.Schema{"type": "string"}},
+		},
+		"examples": []any{"schedule.json", []string{"schedule.json", "schedules/*.json"}},
+	}
+}
+
+func (pl *pathList) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		if !strings.HasPrefix(strings.TrimSpace(str), "[") {
+			*pl = pathList{str}
+			return nil
+		}
+
+		data = []byte(str)
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*pl = pathList(list)
+	return nil
+}
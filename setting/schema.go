@@ -0,0 +1,11 @@
+package setting
+
+import (
+	"time-meter/jsonschema"
+)
+
+func JSONSchema() jsonschema.Schema {
+	ret := jsonschema.Generate(nilableSettings{}, "TimeMeter settings")
+	ret["properties"].(jsonschema.Schema)["$schema"] = jsonschema.Schema{"type": "string"}
+	return ret
+}
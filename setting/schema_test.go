@@ -0,0 +1,56 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time-meter/jsonschema"
+)
+
+func TestSettingsFilesMatchSchema(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "settings.json")
+
+	jsonText := `{
+		"$schema": "http://localhost:50000/schema/settings.json",
+		"meter_width": 80,
+		"meter_opacity": 200,
+		"past_minutes": 30,
+		"future_duration": "2h30m",
+		"schedule_files": "a.json;b.json",
+		"task_store": "sqlite",
+		"working_hours_begin": "08:30",
+		"working_days": ["mon", "tue"],
+		"breaks": ["12:00-13:00"],
+		"background_color": "rgba(0, 0, 0, 50%)",
+		"theme": "dark",
+		"themes": {"dark": {"chart_color": "#336699"}},
+		"profiles": {"focus": {"future_duration": "1h", "theme": "dark"}}
+	}`
+
+	if err := os.WriteFile(filename, []byte(jsonText), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jsonschema.Validate(JSONSchema(), []byte(jsonText)); err != nil {
+		t.Fatalf("hand-written settings: %v", err)
+	}
+
+	var s Settings
+	if err := s.LoadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if err := s.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if err := jsonschema.Validate(JSONSchema(), jsonBytes); err != nil {
+		t.Errorf("saved settings: %v", err)
+	}
+
+	if err := jsonschema.Validate(JSONSchema(), []byte(`{"meter_width": "wide", "unknown": 1}`)); err == nil {
+		t.Error("invalid settings matched the schema")
+	}
+}
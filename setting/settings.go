@@ -4,9 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"reflect"
+	"strings"
 	"time"
+	"time-meter/color"
+)
+
+const (
+	FILE_WATCHER_AUTO     = "auto"
+	FILE_WATCHER_FSNOTIFY = "fsnotify"
+	FILE_WATCHER_POLLING  = "polling"
+)
 
-	"github.com/cwchiu/go-winapi"
+const (
+	TASK_STORE_JSON   = "json"
+	TASK_STORE_SQLITE = "sqlite"
 )
 
 type Settings struct {
@@ -17,30 +29,68 @@ type Settings struct {
 	FutureDuration      time.Duration
 	ScaleInterval       time.Duration
 	ScheduleEditCommand string
-	BackgroundColor     winapi.COLORREF
-	MainScaleColor      winapi.COLORREF
-	SubScalesColor      winapi.COLORREF
-	ChartColor          winapi.COLORREF
-	TipTextColor        winapi.COLORREF
+	ScheduleFiles       []string
+	TaskStore           string
+	SqliteFile          string
+	WorkingHoursBegin   time.Duration
+	WorkingHoursEnd     time.Duration
+	WorkingDays         []time.Weekday
+	Breaks              []ClockRange
+	SlotBuffer          time.Duration
+	MinimumSlot         time.Duration
+	FileWatcher         string
+	PollingInterval     time.Duration
+	BackgroundColor     color.Color
+	MainScaleColor      color.Color
+	SubScalesColor      color.Color
+	ChartColor          color.Color
+	TipTextColor        color.Color
 	Port                int
 	ServerEnabled       bool
+	ChartVisible        bool
+	SubScalesVisible    bool
+	Language            string
+	Theme               string
+	Profile             string
+	source              nilableSettings
 }
 
 type nilableSettings struct {
-	TargetDisplayIndex   *int            `json:"target_display_index,omitempty"`
-	MeterWidth           *int            `json:"meter_width,omitempty"`
-	MeterOpacity         *byte           `json:"meter_opacity,omitempty"`
-	PastMinutes          *durationMinute `json:"past_minutes,omitempty"`
-	FutureMinutes        *durationMinute `json:"future_minutes,omitempty"`
-	ScaleIntervalMinutes *durationMinute `json:"scale_interval_minutes,omitempty"`
-	ScheduleEditCommand  *string         `json:"schedule_edit_command,omitempty"`
-	BackgroundColor      *colorHexString `json:"background_color,omitempty"`
-	MainScaleColor       *colorHexString `json:"main_scale_color,omitempty"`
-	SubScalesColor       *colorHexString `json:"sub_scales_color,omitempty"`
-	ChartColor           *colorHexString `json:"chart_color,omitempty"`
-	TipTextColor         *colorHexString `json:"tip_text_color,omitempty"`
-	Port                 *int            `json:"port,omitempty"`
-	ServerEnabled        *bool           `json:"server_enabled,omitempty"`
+	TargetDisplayIndex   *int                       `json:"target_display_index,omitempty"`
+	MeterWidth           *int                       `json:"meter_width,omitempty"`
+	MeterOpacity         *byte                      `json:"meter_opacity,omitempty"`
+	PastMinutes          *durationMinute            `json:"past_minutes,omitempty"`
+	FutureMinutes        *durationMinute            `json:"future_minutes,omitempty"`
+	ScaleIntervalMinutes *durationMinute            `json:"scale_interval_minutes,omitempty"`
+	PastDuration         *durationString            `json:"past_duration,omitempty"`
+	FutureDuration       *durationString            `json:"future_duration,omitempty"`
+	ScaleInterval        *durationString            `json:"scale_interval,omitempty"`
+	ScheduleEditCommand  *string                    `json:"schedule_edit_command,omitempty"`
+	ScheduleFiles        *pathList                  `json:"schedule_files,omitempty"`
+	TaskStore            *string                    `json:"task_store,omitempty"`
+	SqliteFile           *string                    `json:"sqlite_file,omitempty"`
+	WorkingHoursBegin    *clockString               `json:"working_hours_begin,omitempty"`
+	WorkingHoursEnd      *clockString               `json:"working_hours_end,omitempty"`
+	WorkingDays          *weekdayList               `json:"working_days,omitempty"`
+	Breaks               *clockRangeList            `json:"breaks,omitempty"`
+	SlotBuffer           *durationString            `json:"slot_buffer,omitempty"`
+	MinimumSlot          *durationString            `json:"minimum_slot,omitempty"`
+	FileWatcher          *string                    `json:"file_watcher,omitempty"`
+	PollingInterval      *durationString            `json:"polling_interval,omitempty"`
+	BackgroundColor      *colorString               `json:"background_color,omitempty"`
+	MainScaleColor       *colorString               `json:"main_scale_color,omitempty"`
+	SubScalesColor       *colorString               `json:"sub_scales_color,omitempty"`
+	ChartColor           *colorString               `json:"chart_color,omitempty"`
+	TipTextColor         *colorString               `json:"tip_text_color,omitempty"`
+	Port                 *int                       `json:"port,omitempty"`
+	ServerEnabled        *bool                      `json:"server_enabled,omitempty"`
+	ChartVisible         *bool                      `json:"chart_visible,omitempty"`
+	SubScalesVisible     *bool                      `json:"sub_scales_visible,omitempty"`
+	Language             *string                    `json:"language,omitempty"`
+	Theme                *string                    `json:"theme,omitempty"`
+	Profile              *string                    `json:"profile,omitempty"`
+	Themes               map[string]nilableSettings `json:"themes,omitempty"`
+	Profiles             map[string]nilableSettings `json:"profiles,omitempty"`
 }
 
 func (s *Settings) Default() {
@@ -51,13 +101,30 @@ func (s *Settings) Default() {
 	s.FutureDuration = time.Hour * 3
 	s.ScaleInterval = time.Hour * 1
 	s.ScheduleEditCommand = "notepad"
-	s.BackgroundColor = winapi.RGB(0, 0, 0)
-	s.MainScaleColor = winapi.RGB(255, 255, 255)
-	s.SubScalesColor = winapi.RGB(128, 128, 128)
-	s.ChartColor = winapi.RGB(255, 128, 0)
-	s.TipTextColor = winapi.RGB(255, 255, 255)
+	s.ScheduleFiles = []string{"schedule.json"}
+	s.TaskStore = TASK_STORE_JSON
+	s.SqliteFile = "time-meter.db"
+	s.WorkingHoursBegin = time.Hour * 9
+	s.WorkingHoursEnd = time.Hour * 18
+	s.WorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	s.Breaks = []ClockRange{}
+	s.SlotBuffer = 0
+	s.MinimumSlot = time.Minute * 15
+	s.FileWatcher = FILE_WATCHER_AUTO
+	s.PollingInterval = time.Second * 2
+	s.BackgroundColor = color.RGB(0, 0, 0)
+	s.MainScaleColor = color.RGB(255, 255, 255)
+	s.SubScalesColor = color.RGB(128, 128, 128)
+	s.ChartColor = color.RGB(255, 128, 0)
+	s.TipTextColor = color.RGB(255, 255, 255)
 	s.Port = 50000
 	s.ServerEnabled = true
+	s.ChartVisible = true
+	s.SubScalesVisible = true
+	s.Language = ""
+	s.Theme = ""
+	s.Profile = ""
+	s.source = nilableSettings{}
 }
 
 func (s *Settings) LoadFile(filename string) error {
@@ -66,35 +133,238 @@ func (s *Settings) LoadFile(filename string) error {
 	if jsonBytes, err := os.ReadFile(filename); err != nil {
 		return err
 
-	} else if err := json.NewDecoder(bytes.NewReader(jsonBytes)).Decode(&nilable); err != nil {
+	} else if err := nilable.decode(jsonBytes); err != nil {
 		return err
+
+	} else if errs := append(nilable.validateCombinations(), nilable.validateReferences()...); 0 < len(errs) {
+		return errs
 	}
 
-	var settings Settings
-	settings.Default()
-
-	assignIfNotNil(&settings.TargetDisplayIndex, nilable.TargetDisplayIndex)
-	assignIfNotNil(&settings.MeterWidth, nilable.MeterWidth)
-	assignIfNotNil(&settings.MeterOpacity, nilable.MeterOpacity)
-	assignIfNotNil(&settings.PastDuration, (*time.Duration)(nilable.PastMinutes))
-	assignIfNotNil(&settings.FutureDuration, (*time.Duration)(nilable.FutureMinutes))
-	assignIfNotNil(&settings.ScaleInterval, (*time.Duration)(nilable.ScaleIntervalMinutes))
-	assignIfNotNil(&settings.ScheduleEditCommand, nilable.ScheduleEditCommand)
-	assignIfNotNil(&settings.BackgroundColor, (*winapi.COLORREF)(nilable.BackgroundColor))
-	assignIfNotNil(&settings.MainScaleColor, (*winapi.COLORREF)(nilable.MainScaleColor))
-	assignIfNotNil(&settings.SubScalesColor, (*winapi.COLORREF)(nilable.SubScalesColor))
-	assignIfNotNil(&settings.ChartColor, (*winapi.COLORREF)(nilable.ChartColor))
-	assignIfNotNil(&settings.TipTextColor, (*winapi.COLORREF)(nilable.TipTextColor))
-	assignIfNotNil(&settings.Port, nilable.Port)
-	assignIfNotNil(&settings.ServerEnabled, nilable.ServerEnabled)
+	settings, err := nilable.resolve(nil, nil)
+	if err != nil {
+		return err
+	}
 
 	*s = settings
 
 	return nil
 }
 
+func (s *Settings) SaveFile(filename string) error {
+	fields := make(map[string]json.RawMessage)
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+	} else if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return err
+	}
+
+	var defaults Settings
+	defaults.Default()
+
+	unthemed := s.unthemed()
+	nilable := newNilableSettings(&unthemed, &defaults)
+
+	value := reflect.ValueOf(&nilable).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		key := jsonKeyOf(value.Type().Field(index))
+		delete(fields, key)
+
+		if value.Field(index).IsNil() {
+			continue
+
+		} else if fieldJson, err := json.Marshal(value.Field(index).Interface()); err != nil {
+			return err
+
+		} else {
+			fields[key] = fieldJson
+		}
+	}
+
+	jsonBuffer := bytes.NewBuffer(nil)
+
+	encoder := json.NewEncoder(jsonBuffer)
+	encoder.SetIndent("", "\t")
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(fields); err != nil {
+		return err
+
+	} else if err := os.WriteFile(filename, jsonBuffer.Bytes(), os.ModePerm); err != nil {
+		return err
+
+	} else {
+		return nil
+	}
+}
+
+func newNilableSettings(s *Settings, base *Settings) nilableSettings {
+	var ret nilableSettings
+
+	ret.TargetDisplayIndex = pointerOf(s.TargetDisplayIndex)
+	ret.MeterWidth = pointerOf(s.MeterWidth)
+	ret.MeterOpacity = pointerOf(s.MeterOpacity)
+	ret.PastDuration = (*durationString)(pointerOf(s.PastDuration))
+	ret.FutureDuration = (*durationString)(pointerOf(s.FutureDuration))
+	ret.ScaleInterval = (*durationString)(pointerOf(s.ScaleInterval))
+	ret.ScheduleEditCommand = pointerOf(s.ScheduleEditCommand)
+	ret.ScheduleFiles = (*pathList)(pointerOf(s.ScheduleFiles))
+	ret.TaskStore = pointerOf(s.TaskStore)
+	ret.SqliteFile = pointerOf(s.SqliteFile)
+	ret.WorkingHoursBegin = (*clockString)(pointerOf(s.WorkingHoursBegin))
+	ret.WorkingHoursEnd = (*clockString)(pointerOf(s.WorkingHoursEnd))
+	ret.WorkingDays = (*weekdayList)(pointerOf(s.WorkingDays))
+	ret.Breaks = (*clockRangeList)(pointerOf(s.Breaks))
+	ret.SlotBuffer = (*durationString)(pointerOf(s.SlotBuffer))
+	ret.MinimumSlot = (*durationString)(pointerOf(s.MinimumSlot))
+	ret.FileWatcher = pointerOf(s.FileWatcher)
+	ret.PollingInterval = (*durationString)(pointerOf(s.PollingInterval))
+	ret.BackgroundColor = (*colorString)(pointerOf(s.BackgroundColor))
+	ret.MainScaleColor = (*colorString)(pointerOf(s.MainScaleColor))
+	ret.SubScalesColor = (*colorString)(pointerOf(s.SubScalesColor))
+	ret.ChartColor = (*colorString)(pointerOf(s.ChartColor))
+	ret.TipTextColor = (*colorString)(pointerOf(s.TipTextColor))
+	ret.Port = pointerOf(s.Port)
+	ret.ServerEnabled = pointerOf(s.ServerEnabled)
+	ret.ChartVisible = pointerOf(s.ChartVisible)
+	ret.SubScalesVisible = pointerOf(s.SubScalesVisible)
+	ret.Language = pointerOf(s.Language)
+	ret.Theme = pointerOf(s.Theme)
+	ret.Profile = pointerOf(s.Profile)
+	ret.Themes = s.source.Themes
+	ret.Profiles = s.source.Profiles
+
+	if base == nil {
+		return ret
+	}
+
+	baseNilable := newNilableSettings(base, nil)
+
+	value := reflect.ValueOf(&ret).Elem()
+	baseValue := reflect.ValueOf(&baseNilable).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		if reflect.DeepEqual(value.Field(index).Interface(), baseValue.Field(index).Interface()) {
+			value.Field(index).SetZero()
+		}
+	}
+
+	return ret
+}
+
+func (ns *nilableSettings) decode(jsonBytes []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(jsonBytes)).Decode(&fields); err != nil {
+		return ValidationErrors{{Path: jsonPath(""), Reason: err.Error()}}
+	}
+
+	return ns.decodeFields(fields, false)
+}
+
+func (ns *nilableSettings) decodeFields(fields map[string]json.RawMessage, lenient bool) error {
+	var errs ValidationErrors
+
+	value := reflect.ValueOf(ns).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		key := jsonKeyOf(value.Type().Field(index))
+		target := value.Field(index).Addr().Interface()
+
+		fieldJson, ok := fields[key]
+		if !ok {
+			continue
+		}
+
+		err := json.Unmarshal(fieldJson, target)
+		if err != nil && lenient {
+			var str string
+			if json.Unmarshal(fieldJson, &str) == nil && json.Unmarshal([]byte(str), target) == nil {
+				err = nil
+			}
+		}
+
+		if err != nil {
+			value.Field(index).SetZero()
+			errs = append(errs, ValidationError{Path: jsonPath(key), Reason: err.Error()})
+		}
+	}
+
+	errs = append(errs, ns.validateFields()...)
+
+	if 0 < len(errs) {
+		return errs
+	}
+
+	ns.normalize()
+
+	return nil
+}
+
+func (ns *nilableSettings) normalize() {
+	if ns.PastDuration == nil {
+		ns.PastDuration = (*durationString)(ns.PastMinutes)
+	}
+
+	if ns.FutureDuration == nil {
+		ns.FutureDuration = (*durationString)(ns.FutureMinutes)
+	}
+
+	if ns.ScaleInterval == nil {
+		ns.ScaleInterval = (*durationString)(ns.ScaleIntervalMinutes)
+	}
+
+	ns.PastMinutes = nil
+	ns.FutureMinutes = nil
+	ns.ScaleIntervalMinutes = nil
+}
+
+func (ns *nilableSettings) apply(s *Settings) {
+	assignIfNotNil(&s.TargetDisplayIndex, ns.TargetDisplayIndex)
+	assignIfNotNil(&s.MeterWidth, ns.MeterWidth)
+	assignIfNotNil(&s.MeterOpacity, ns.MeterOpacity)
+	assignIfNotNil(&s.PastDuration, (*time.Duration)(ns.PastMinutes))
+	assignIfNotNil(&s.FutureDuration, (*time.Duration)(ns.FutureMinutes))
+	assignIfNotNil(&s.ScaleInterval, (*time.Duration)(ns.ScaleIntervalMinutes))
+	assignIfNotNil(&s.PastDuration, (*time.Duration)(ns.PastDuration))
+	assignIfNotNil(&s.FutureDuration, (*time.Duration)(ns.FutureDuration))
+	assignIfNotNil(&s.ScaleInterval, (*time.Duration)(ns.ScaleInterval))
+	assignIfNotNil(&s.ScheduleEditCommand, ns.ScheduleEditCommand)
+	assignIfNotNil(&s.ScheduleFiles, (*[]string)(ns.ScheduleFiles))
+	assignIfNotNil(&s.TaskStore, ns.TaskStore)
+	assignIfNotNil(&s.SqliteFile, ns.SqliteFile)
+	assignIfNotNil(&s.WorkingHoursBegin, (*time.Duration)(ns.WorkingHoursBegin))
+	assignIfNotNil(&s.WorkingHoursEnd, (*time.Duration)(ns.WorkingHoursEnd))
+	assignIfNotNil(&s.WorkingDays, (*[]time.Weekday)(ns.WorkingDays))
+	assignIfNotNil(&s.Breaks, (*[]ClockRange)(ns.Breaks))
+	assignIfNotNil(&s.SlotBuffer, (*time.Duration)(ns.SlotBuffer))
+	assignIfNotNil(&s.MinimumSlot, (*time.Duration)(ns.MinimumSlot))
+	assignIfNotNil(&s.FileWatcher, ns.FileWatcher)
+	assignIfNotNil(&s.PollingInterval, (*time.Duration)(ns.PollingInterval))
+	assignIfNotNil(&s.BackgroundColor, (*color.Color)(ns.BackgroundColor))
+	assignIfNotNil(&s.MainScaleColor, (*color.Color)(ns.MainScaleColor))
+	assignIfNotNil(&s.SubScalesColor, (*color.Color)(ns.SubScalesColor))
+	assignIfNotNil(&s.ChartColor, (*color.Color)(ns.ChartColor))
+	assignIfNotNil(&s.TipTextColor, (*color.Color)(ns.TipTextColor))
+	assignIfNotNil(&s.Port, ns.Port)
+	assignIfNotNil(&s.ServerEnabled, ns.ServerEnabled)
+	assignIfNotNil(&s.ChartVisible, ns.ChartVisible)
+	assignIfNotNil(&s.SubScalesVisible, ns.SubScalesVisible)
+	assignIfNotNil(&s.Language, ns.Language)
+	assignIfNotNil(&s.Theme, ns.Theme)
+	assignIfNotNil(&s.Profile, ns.Profile)
+}
+
+func jsonKeyOf(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
 func assignIfNotNil[T any](d *T, s *T) {
 	if s != nil {
 		*d = *s
 	}
 }
+
+func pointerOf[T any](v T) *T {
+	return &v
+}
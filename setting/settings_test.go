@@ -0,0 +1,133 @@
+package setting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"time-meter/color"
+)
+
+func saveAndReload(t *testing.T, filename string) (Settings, Settings, map[string]json.RawMessage) {
+	t.Helper()
+
+	var loaded Settings
+	if err := loaded.LoadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loaded.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded Settings
+	if err := reloaded.LoadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	return loaded, reloaded, fields
+}
+
+func TestSaveFileRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "settings.json")
+
+	jsonText := `{
+		"meter_width": 80,
+		"past_duration": "90m",
+		"schedule_files": ["a.json", "b.json"],
+		"working_hours_begin": "08:30",
+		"working_days": ["sun", "sat"],
+		"breaks": ["12:00-13:00", "15:00-15:15"],
+		"background_color": "#11223344",
+		"server_enabled": false,
+		"themes": {"dark": {"chart_color": "#336699"}},
+		"custom_key": {"kept": true}
+	}`
+
+	if err := os.WriteFile(filename, []byte(jsonText), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, reloaded, fields := saveAndReload(t, filename)
+
+	if !reflect.DeepEqual(newNilableSettings(&loaded, nil), newNilableSettings(&reloaded, nil)) {
+		t.Errorf("reloaded %+v, expected %+v", reloaded, loaded)
+	}
+
+	if reloaded.MeterWidth != 80 || reloaded.PastDuration != time.Minute*90 || reloaded.WorkingHoursBegin != time.Hour*8+time.Minute*30 {
+		t.Errorf("lost values %+v", reloaded)
+	}
+
+	if reloaded.BackgroundColor != (color.Color{R: 0x11, G: 0x22, B: 0x33, A: 0x44}) || reloaded.ServerEnabled {
+		t.Errorf("lost values %+v", reloaded)
+	}
+
+	if !reflect.DeepEqual(reloaded.WorkingDays, []time.Weekday{time.Sunday, time.Saturday}) {
+		t.Errorf("working days are %v", reloaded.WorkingDays)
+	}
+
+	if !reflect.DeepEqual(reloaded.Breaks, []ClockRange{{time.Hour * 12, time.Hour * 13}, {time.Hour * 15, time.Hour*15 + time.Minute*15}}) {
+		t.Errorf("breaks are %v", reloaded.Breaks)
+	}
+
+	var custom map[string]bool
+	if err := json.Unmarshal(fields["custom_key"], &custom); err != nil || !custom["kept"] {
+		t.Errorf("unknown field is %s", fields["custom_key"])
+	}
+
+	if _, ok := fields["themes"]; !ok {
+		t.Error("themes were dropped")
+	}
+
+	for _, key := range []string{"meter_opacity", "future_duration", "port", "chart_visible"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("default value %s was saved", key)
+		}
+	}
+}
+
+func TestSaveFileMigratesLegacyMinutes(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "settings.json")
+
+	if err := os.WriteFile(filename, []byte(`{"future_minutes": 240}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, reloaded, fields := saveAndReload(t, filename)
+
+	if loaded.FutureDuration != time.Hour*4 || reloaded.FutureDuration != time.Hour*4 {
+		t.Errorf("future duration is %v, then %v", loaded.FutureDuration, reloaded.FutureDuration)
+	}
+
+	if _, ok := fields["future_minutes"]; ok {
+		t.Error("the legacy key was kept next to its replacement")
+	}
+}
+
+func TestSaveFileOfDefaultsIsEmpty(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "settings.json")
+
+	var s Settings
+	s.Default()
+
+	if err := s.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if string(jsonBytes) != "{}\n" {
+		t.Errorf("saved %s", jsonBytes)
+	}
+}
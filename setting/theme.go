@@ -0,0 +1,167 @@
+package setting
+
+import (
+	"fmt"
+	"sort"
+)
+
+var themeKeys = map[string]bool{
+	"meter_width":      true,
+	"meter_opacity":    true,
+	"background_color": true,
+	"main_scale_color": true,
+	"sub_scales_color": true,
+	"chart_color":      true,
+	"tip_text_color":   true,
+}
+
+var profileExcludedKeys = map[string]bool{
+	"profile":  true,
+	"themes":   true,
+	"profiles": true,
+}
+
+func (s *Settings) ThemeNames() []string {
+	return sortedKeysOf(s.source.Themes)
+}
+
+func (s *Settings) ProfileNames() []string {
+	return sortedKeysOf(s.source.Profiles)
+}
+
+func (s *Settings) SwitchTheme(name string) (Settings, error) {
+	profile := s.Profile
+	return s.source.resolve(&profile, &name)
+}
+
+func (s *Settings) SwitchProfile(name string) (Settings, error) {
+	return s.source.resolve(&name, nil)
+}
+
+func (s *Settings) unthemed() Settings {
+	if s.Theme == "" && s.Profile == "" {
+		return *s
+	}
+
+	var ret Settings
+	ret.Default()
+	s.source.apply(&ret)
+	ret.source = s.source
+
+	return ret
+}
+
+func (ns *nilableSettings) resolve(profileName *string, themeName *string) (Settings, error) {
+	var ret Settings
+	ret.Default()
+	ns.apply(&ret)
+	ret.source = *ns
+
+	if profileName != nil {
+		ret.Profile = *profileName
+		ret.Theme = ""
+		assignIfNotNil(&ret.Theme, ns.Theme)
+	}
+
+	if ret.Profile != "" {
+		profile, ok := ns.Profiles[ret.Profile]
+		if !ok {
+			return ret, fmt.Errorf(`unknown profile "%s"`, ret.Profile)
+		}
+
+		profile.apply(&ret)
+	}
+
+	if themeName != nil {
+		ret.Theme = *themeName
+	}
+
+	if ret.Theme != "" {
+		theme, ok := ns.Themes[ret.Theme]
+		if !ok {
+			return ret, fmt.Errorf(`unknown theme "%s"`, ret.Theme)
+		}
+
+		theme.apply(&ret)
+	}
+
+	return ret, nil
+}
+
+func (ns *nilableSettings) validateThemes() ValidationErrors {
+	var ret ValidationErrors
+
+	for _, name := range sortedKeysOf(ns.Themes) {
+		theme := ns.Themes[name]
+		ret = append(ret, theme.validateNested("themes."+name, func(key string) bool {
+			return themeKeys[key]
+		})...)
+	}
+
+	for _, name := range sortedKeysOf(ns.Profiles) {
+		profile := ns.Profiles[name]
+		ret = append(ret, profile.validateNested("profiles."+name, func(key string) bool {
+			return !profileExcludedKeys[key]
+		})...)
+
+	}
+
+	return ret
+}
+
+func (ns *nilableSettings) validateReferences() ValidationErrors {
+	var ret ValidationErrors
+
+	for _, name := range sortedKeysOf(ns.Profiles) {
+		profile := ns.Profiles[name]
+
+		if profile.Theme != nil && *profile.Theme != "" {
+			if _, ok := ns.Themes[*profile.Theme]; !ok {
+				ret = append(ret, ValidationError{Path: jsonPath("profiles." + name + ".theme"), Reason: "unknown theme"})
+			}
+		}
+	}
+
+	if ns.Theme != nil && *ns.Theme != "" {
+		if _, ok := ns.Themes[*ns.Theme]; !ok {
+			ret = append(ret, ValidationError{Path: jsonPath("theme"), Reason: "unknown theme"})
+		}
+	}
+
+	if ns.Profile != nil && *ns.Profile != "" {
+		if _, ok := ns.Profiles[*ns.Profile]; !ok {
+			ret = append(ret, ValidationError{Path: jsonPath("profile"), Reason: "unknown profile"})
+		}
+	}
+
+	return ret
+}
+
+func (ns *nilableSettings) validateNested(path string, allowed func(key string) bool) ValidationErrors {
+	var ret ValidationErrors
+
+	for _, key := range ns.keys() {
+		if !allowed(key) {
+			ret = append(ret, ValidationError{Path: jsonPath(path + "." + key), Reason: "not allowed here"})
+		}
+	}
+
+	for _, err := range ns.validateFields() {
+		err.Path = jsonPath(path) + err.Path[len(jsonPath("")):]
+		ret = append(ret, err)
+	}
+
+	return ret
+}
+
+func sortedKeysOf[T any](m map[string]T) []string {
+	ret := []string{}
+
+	for key := range m {
+		ret = append(ret, key)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
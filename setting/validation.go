@@ -0,0 +1,166 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ValidationError struct {
+	Source string
+	Path   string
+	Reason string
+}
+
+type ValidationErrors []ValidationError
+
+func (ve ValidationError) Error() string {
+	if ve.Source != "" {
+		return fmt.Sprintf("%s: %s: %s", ve.Source, ve.Path, ve.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s", ve.Path, ve.Reason)
+}
+
+func (ve ValidationErrors) Error() string {
+	lines := []string{}
+
+	for _, e := range ve {
+		lines = append(lines, e.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func jsonPath(key string) string {
+	if key == "" {
+		return "$"
+	}
+
+	return "$." + key
+}
+
+func (ns *nilableSettings) validateFields() ValidationErrors {
+	var ret ValidationErrors
+
+	check := func(ok bool, key string, reason string) {
+		if !ok {
+			ret = append(ret, ValidationError{Path: jsonPath(key), Reason: reason})
+		}
+	}
+
+	if v := ns.TargetDisplayIndex; v != nil {
+		check(0 <= *v, "target_display_index", "must be 0 or greater")
+	}
+
+	if v := ns.MeterWidth; v != nil {
+		check(0 < *v, "meter_width", "must be greater than 0")
+	}
+
+	if v := ns.PastMinutes; v != nil {
+		check(0 <= *v, "past_minutes", "must be 0 or greater")
+	}
+
+	if v := ns.FutureMinutes; v != nil {
+		check(0 <= *v, "future_minutes", "must be 0 or greater")
+	}
+
+	if v := ns.ScaleIntervalMinutes; v != nil {
+		check(0 < *v, "scale_interval_minutes", "must be greater than 0")
+	}
+
+	if v := ns.PastDuration; v != nil {
+		check(0 <= *v, "past_duration", "must be 0 or greater")
+	}
+
+	if v := ns.FutureDuration; v != nil {
+		check(0 <= *v, "future_duration", "must be 0 or greater")
+	}
+
+	if v := ns.ScaleInterval; v != nil {
+		check(durationString(time.Second) <= *v, "scale_interval", "must be 1 second or greater")
+	}
+
+	if v := ns.ScheduleEditCommand; v != nil {
+		check(*v != "", "schedule_edit_command", "must not be empty")
+	}
+
+	if v := ns.ScheduleFiles; v != nil {
+		check(0 < len(*v), "schedule_files", "must not be empty")
+
+		for index, pattern := range *v {
+			check(pattern != "", fmt.Sprintf("schedule_files[%d]", index), "must not be empty")
+		}
+	}
+
+	if v := ns.SlotBuffer; v != nil {
+		check(0 <= *v, "slot_buffer", "must be 0 or greater")
+	}
+
+	if v := ns.MinimumSlot; v != nil {
+		check(0 <= *v, "minimum_slot", "must be 0 or greater")
+	}
+
+	if v := ns.TaskStore; v != nil {
+		check(*v == TASK_STORE_JSON || *v == TASK_STORE_SQLITE, "task_store", `must be "json" or "sqlite"`)
+	}
+
+	if v := ns.SqliteFile; v != nil {
+		check(*v != "", "sqlite_file", "must not be empty")
+	}
+
+	if v := ns.FileWatcher; v != nil {
+		check(*v == FILE_WATCHER_AUTO || *v == FILE_WATCHER_FSNOTIFY || *v == FILE_WATCHER_POLLING, "file_watcher", `must be "auto", "fsnotify" or "polling"`)
+	}
+
+	if v := ns.PollingInterval; v != nil {
+		check(durationString(time.Millisecond*100) <= *v, "polling_interval", "must be 100 milliseconds or greater")
+	}
+
+	if v := ns.Port; v != nil {
+		check(0 < *v && *v <= 65535, "port", "must be between 1 and 65535")
+	}
+
+	ret = append(ret, ns.validateThemes()...)
+
+	return ret
+}
+
+func (ns *nilableSettings) validateCombinations() ValidationErrors {
+	var settings Settings
+	settings.Default()
+	ns.apply(&settings)
+
+	ret := ns.validateCombinationsOf(&settings, "")
+
+	for _, name := range sortedKeysOf(ns.Profiles) {
+		profile := ns.Profiles[name]
+
+		profileSettings := settings
+		profile.apply(&profileSettings)
+
+		ret = append(ret, profile.validateCombinationsOf(&profileSettings, "profiles."+name+".")...)
+	}
+
+	return ret
+}
+
+func (ns *nilableSettings) validateCombinationsOf(settings *Settings, prefix string) ValidationErrors {
+	var ret ValidationErrors
+
+	check := func(ok bool, key string, reason string) {
+		if !ok {
+			ret = append(ret, ValidationError{Path: jsonPath(prefix + key), Reason: reason})
+		}
+	}
+
+	if ns.PastDuration != nil || ns.FutureDuration != nil || ns.PastMinutes != nil || ns.FutureMinutes != nil {
+		check(time.Second <= settings.PastDuration+settings.FutureDuration, "future_duration", "past and future must total 1 second or greater")
+	}
+
+	if ns.WorkingHoursBegin != nil || ns.WorkingHoursEnd != nil {
+		check(settings.WorkingHoursBegin < settings.WorkingHoursEnd, "working_hours_end", "must be later than working_hours_begin")
+	}
+
+	return ret
+}
@@ -0,0 +1,59 @@
+package setting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"time-meter/jsonschema"
+)
+
+type weekdayList []time.Weekday
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func (wl *weekdayList) MarshalJSON() ([]byte, error) {
+	names := []string{}
+
+	for _, weekday := range *wl {
+		names = append(names, weekdayNames[weekday])
+	}
+
+	return json.Marshal(names)
+}
+
+func (wl *weekdayList) JSONSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"type":        "array",
+		"items":       jsonschema.Schema{"enum": weekdayNames},
+		"uniqueItems": true,
+		"examples":    [][]string{{"mon", "tue", "wed", "thu", "fri"}},
+	}
+}
+
+func (wl *weekdayList) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+
+	ret := weekdayList{}
+
+	for _, name := range names {
+		found := false
+
+		for weekday, weekdayName := range weekdayNames {
+			if strings.EqualFold(name, weekdayName) {
+				ret = append(ret, time.Weekday(weekday))
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf(`invalid weekday "%s", expected one of %s`, name, strings.Join(weekdayNames, ", "))
+		}
+	}
+
+	*wl = ret
+	return nil
+}
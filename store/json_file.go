@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+	"time-meter/logic"
+	"time-meter/watcher"
+)
+
+type WatcherFactory func(patterns []string) watcher.Watcher
+
+type jsonFileStore struct {
+	handlers
+	mutex       sync.Mutex
+	scheduleSet *logic.ScheduleSet
+	newWatcher  WatcherFactory
+	watcher     watcher.Watcher
+	loaded      bool
+}
+
+func NewJsonFile(patterns []string, newWatcher WatcherFactory) TaskStore {
+	ret := new(jsonFileStore)
+	ret.scheduleSet = logic.NewScheduleSet(patterns)
+	ret.newWatcher = newWatcher
+	return ret
+}
+
+func (s *jsonFileStore) Load() ([]logic.Task, error) {
+	s.mutex.Lock()
+	if !s.loaded {
+		s.scheduleSet.ReloadAll()
+		s.loaded = true
+	}
+	s.mutex.Unlock()
+
+	tasks := s.scheduleSet.Tasks()
+
+	if failures := s.scheduleSet.Failures(); 0 < len(failures) {
+		return tasks, &LoadError{Failures: failures}
+	}
+
+	return tasks, nil
+}
+
+func (s *jsonFileStore) Save(tasks []logic.Task) error {
+	if err := s.scheduleSet.Save(tasks); err != nil {
+		return err
+	}
+
+	s.notifyChanged()
+
+	return nil
+}
+
+func (s *jsonFileStore) Between(beginAt time.Time, endAt time.Time) ([]logic.Task, error) {
+	tasks, err := s.Load()
+	return between(tasks, beginAt, endAt), err
+}
+
+func (s *jsonFileStore) Watch(ctx context.Context) error {
+	done := s.Start()
+
+	s.watcher = s.newWatcher(s.scheduleSet.WatchPatterns())
+
+	s.watcher.OnChanged(func(filename string) {
+		s.mutex.Lock()
+		s.loaded = true
+		s.mutex.Unlock()
+
+		s.scheduleSet.Reload(filename)
+		s.notifyChanged()
+	})
+
+	s.watcher.OnError(func(err error) {
+		s.NotifyError(err)
+	})
+
+	if err := s.watcher.Watch(ctx, s.scheduleSet.WatchPatterns()...); err != nil {
+		close(done)
+		return err
+	}
+
+	go func() {
+		s.watcher.Wait()
+		close(done)
+	}()
+
+	return nil
+}
+
+func (s *jsonFileStore) Close() error {
+	return nil
+}
+
+func (s *jsonFileStore) Location() string {
+	return s.scheduleSet.Primary()
+}
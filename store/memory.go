@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+	"time-meter/logic"
+)
+
+type memoryStore struct {
+	handlers
+	mutex sync.Mutex
+	tasks []logic.Task
+}
+
+func NewMemory(tasks []logic.Task) TaskStore {
+	ret := new(memoryStore)
+	ret.tasks = append([]logic.Task{}, tasks...)
+	return ret
+}
+
+func (s *memoryStore) Load() ([]logic.Task, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]logic.Task{}, s.tasks...), nil
+}
+
+func (s *memoryStore) Save(tasks []logic.Task) error {
+	s.mutex.Lock()
+	s.tasks = append([]logic.Task{}, tasks...)
+	s.mutex.Unlock()
+
+	s.notifyChanged()
+
+	return nil
+}
+
+func (s *memoryStore) Between(beginAt time.Time, endAt time.Time) ([]logic.Task, error) {
+	tasks, err := s.Load()
+	return between(tasks, beginAt, endAt), err
+}
+
+func (s *memoryStore) Watch(ctx context.Context) error {
+	done := s.Start()
+
+	go func() {
+		<-ctx.Done()
+		close(done)
+	}()
+
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+func (s *memoryStore) Location() string {
+	return ""
+}
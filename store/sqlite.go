@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"time-meter/logic"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	seq      INTEGER PRIMARY KEY AUTOINCREMENT,
+	id       TEXT    NOT NULL DEFAULT '',
+	subject  TEXT    NOT NULL,
+	begin_at INTEGER NOT NULL,
+	end_at   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tasks_begin_at ON tasks (begin_at);
+CREATE INDEX IF NOT EXISTS tasks_end_at ON tasks (end_at);
+`
+
+type sqliteRow struct {
+	seq     int64
+	id      string
+	subject string
+	beginAt int64
+	endAt   int64
+}
+
+type sqliteStore struct {
+	handlers
+	db       *sql.DB
+	filename string
+	interval time.Duration
+}
+
+func NewSqlite(filename string, interval time.Duration) (TaskStore, error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	ret := new(sqliteStore)
+	ret.db = db
+	ret.filename = filename
+	ret.interval = interval
+	return ret, nil
+}
+
+func (s *sqliteStore) Load() ([]logic.Task, error) {
+	return s.query(`SELECT id, subject, begin_at, end_at FROM tasks ORDER BY seq`)
+}
+
+func (s *sqliteStore) Save(tasks []logic.Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := saveTasksDiff(tx, tasks); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.notifyChanged()
+
+	return nil
+}
+
+func (s *sqliteStore) Between(beginAt time.Time, endAt time.Time) ([]logic.Task, error) {
+	return s.query(`SELECT id, subject, begin_at, end_at FROM tasks WHERE begin_at < ? AND ? < end_at ORDER BY begin_at, seq`,
+		endAt.UnixNano(), beginAt.UnixNano())
+}
+
+func (s *sqliteStore) Watch(ctx context.Context) error {
+	done := s.Start()
+
+	version, err := s.dataVersion()
+	if err != nil {
+		close(done)
+		return err
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if current, err := s.dataVersion(); err != nil {
+					s.NotifyError(err)
+
+				} else if current != version {
+					version = current
+					s.notifyChanged()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteStore) Location() string {
+	return s.filename
+}
+
+func (s *sqliteStore) query(query string, args ...any) ([]logic.Task, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []logic.Task{}
+
+	for rows.Next() {
+		var task logic.Task
+		var beginAt, endAt int64
+
+		if err := rows.Scan(&task.ID, &task.Subject, &beginAt, &endAt); err != nil {
+			return nil, err
+		}
+
+		task.BeginAt = time.Unix(0, beginAt)
+		task.EndAt = time.Unix(0, endAt)
+		task.Source = s.filename
+
+		ret = append(ret, task)
+	}
+
+	return ret, rows.Err()
+}
+
+func saveTasksDiff(tx *sql.Tx, tasks []logic.Task) error {
+	rows, err := tx.Query(`SELECT seq, id, subject, begin_at, end_at FROM tasks ORDER BY seq`)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string][]sqliteRow)
+
+	for rows.Next() {
+		var row sqliteRow
+		if err := rows.Scan(&row.seq, &row.id, &row.subject, &row.beginAt, &row.endAt); err != nil {
+			rows.Close()
+			return err
+		}
+
+		key := row.key()
+		stored[key] = append(stored[key], row)
+	}
+
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		row := sqliteRowOf(task)
+		key := row.key()
+
+		if candidates := stored[key]; 0 < len(candidates) {
+			current := candidates[0]
+			stored[key] = candidates[1:]
+
+			if current.subject == row.subject && current.beginAt == row.beginAt && current.endAt == row.endAt {
+				continue
+			}
+
+			if _, err := tx.Exec(`UPDATE tasks SET subject = ?, begin_at = ?, end_at = ? WHERE seq = ?`,
+				row.subject, row.beginAt, row.endAt, current.seq); err != nil {
+				return err
+			}
+
+		} else if _, err := tx.Exec(`INSERT INTO tasks (id, subject, begin_at, end_at) VALUES (?, ?, ?, ?)`,
+			row.id, row.subject, row.beginAt, row.endAt); err != nil {
+			return err
+		}
+	}
+
+	for _, candidates := range stored {
+		for _, row := range candidates {
+			if _, err := tx.Exec(`DELETE FROM tasks WHERE seq = ?`, row.seq); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func sqliteRowOf(task logic.Task) sqliteRow {
+	return sqliteRow{
+		id:      task.ID,
+		subject: task.Subject,
+		beginAt: task.BeginAt.UnixNano(),
+		endAt:   task.EndAt.UnixNano(),
+	}
+}
+
+func (r sqliteRow) key() string {
+	if r.id != "" {
+		return "id:" + r.id
+	}
+
+	return fmt.Sprintf("task:%d:%d:%s", r.beginAt, r.endAt, r.subject)
+}
+
+func (s *sqliteStore) dataVersion() (int64, error) {
+	var ret int64
+	err := s.db.QueryRow(`PRAGMA data_version`).Scan(&ret)
+	return ret, err
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"time-meter/logic"
+	"time-meter/watcher"
+)
+
+type TaskStore interface {
+	Load() ([]logic.Task, error)
+	Save(tasks []logic.Task) error
+	Between(beginAt time.Time, endAt time.Time) ([]logic.Task, error)
+	Watch(ctx context.Context) error
+	Wait()
+	Close() error
+	Location() string
+	OnChanged(handler ChangedHandler)
+	OnError(handler ErrorHandler)
+}
+
+type ChangedHandler func()
+
+type ErrorHandler = watcher.ErrorHandler
+
+type LoadError struct {
+	Failures []logic.ScheduleFailure
+}
+
+func (e *LoadError) Error() string {
+	messages := []string{}
+
+	for _, failure := range e.Failures {
+		messages = append(messages, fmt.Sprintf("%s: %s", failure.Filename, failure.Err.Error()))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func FailuresOf(err error, location string) []logic.ScheduleFailure {
+	if err == nil {
+		return []logic.ScheduleFailure{}
+	}
+
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		return loadErr.Failures
+	}
+
+	return []logic.ScheduleFailure{{Filename: location, Err: err}}
+}
+
+type handlers struct {
+	watcher.Handlers
+}
+
+func (h *handlers) OnChanged(handler ChangedHandler) {
+	if handler == nil {
+		h.Handlers.OnChanged(nil)
+		return
+	}
+
+	h.Handlers.OnChanged(func(filename string) {
+		handler()
+	})
+}
+
+func (h *handlers) notifyChanged() {
+	h.NotifyChanged("")
+}
+
+func between(tasks []logic.Task, beginAt time.Time, endAt time.Time) []logic.Task {
+	ret := []logic.Task{}
+
+	for _, task := range tasks {
+		if task.OverlapWith(beginAt, endAt) {
+			ret = append(ret, task)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"time-meter/logic"
+	"time-meter/watcher"
+)
+
+func sampleTasks() []logic.Task {
+	beginAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	return []logic.Task{
+		{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)},
+		{Subject: "B", BeginAt: beginAt.Add(time.Hour), EndAt: beginAt.Add(time.Hour * 2)},
+		{ID: "c", Subject: "C", BeginAt: beginAt.Add(time.Hour * 3), EndAt: beginAt.Add(time.Hour * 4)},
+	}
+}
+
+func newStores(t *testing.T) map[string]TaskStore {
+	dir := t.TempDir()
+
+	sqliteStore, err := NewSqlite(filepath.Join(dir, "tasks.db"), time.Millisecond*20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	return map[string]TaskStore{
+		"json":   NewJsonFile([]string{filepath.Join(dir, "schedule.json")}, nil),
+		"memory": NewMemory(nil),
+		"sqlite": sqliteStore,
+	}
+}
+
+func assertSameTasks(t *testing.T, name string, actual []logic.Task, expected []logic.Task) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("%s: %d tasks, expected %d", name, len(actual), len(expected))
+	}
+
+	for index := range expected {
+		a, e := actual[index], expected[index]
+
+		if a.ID != e.ID || a.Subject != e.Subject || !a.BeginAt.Equal(e.BeginAt) || !a.EndAt.Equal(e.EndAt) {
+			t.Errorf("%s: task %d is %+v, expected %+v", name, index, a, e)
+		}
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	for name, taskStore := range newStores(t) {
+		changed := 0
+		taskStore.OnChanged(func() {
+			changed++
+		})
+
+		tasks := sampleTasks()
+		if err := taskStore.Save(tasks); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if changed != 1 {
+			t.Errorf("%s: notified %d times on save", name, changed)
+		}
+
+		loaded, err := taskStore.Load()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assertSameTasks(t, name, loaded, tasks)
+
+		tasks[0].Subject = "A2"
+		tasks = tasks[:2]
+		tasks = append(tasks, logic.Task{ID: "d", Subject: "D", BeginAt: tasks[1].EndAt, EndAt: tasks[1].EndAt.Add(time.Hour)})
+
+		if err := taskStore.Save(tasks); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		loaded, err = taskStore.Load()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assertSameTasks(t, name, loaded, tasks)
+
+		between, err := taskStore.Between(tasks[1].BeginAt, tasks[1].EndAt)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assertSameTasks(t, name+" between", between, tasks[1:2])
+	}
+}
+
+func TestSqliteSaveUpdatesOnlyChangedRows(t *testing.T) {
+	taskStore, err := NewSqlite(filepath.Join(t.TempDir(), "tasks.db"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taskStore.Close()
+
+	db := taskStore.(*sqliteStore).db
+
+	seqs := func() map[string]int64 {
+		rows, err := db.Query(`SELECT seq, subject FROM tasks`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		ret := make(map[string]int64)
+		for rows.Next() {
+			var seq int64
+			var subject string
+
+			if err := rows.Scan(&seq, &subject); err != nil {
+				t.Fatal(err)
+			}
+
+			ret[subject] = seq
+		}
+
+		return ret
+	}
+
+	tasks := sampleTasks()
+	if err := taskStore.Save(tasks); err != nil {
+		t.Fatal(err)
+	}
+	before := seqs()
+
+	tasks[2].Subject = "C2"
+	if err := taskStore.Save(tasks[1:]); err != nil {
+		t.Fatal(err)
+	}
+	after := seqs()
+
+	expected := map[string]int64{"B": before["B"], "C2": before["C"]}
+	if !reflect.DeepEqual(after, expected) {
+		t.Errorf("rows are %v, expected %v", after, expected)
+	}
+}
+
+func TestJsonFileLoadError(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	broken := filepath.Join(dir, "broken.json")
+
+	if err := logic.SaveScheduleToFile(good, logic.NewSchedule(sampleTasks())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(broken, []byte(`{`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	taskStore := NewJsonFile([]string{good, broken}, nil)
+
+	tasks, err := taskStore.Load()
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(loadErr.Failures) != 1 || loadErr.Failures[0].Filename != broken {
+		t.Errorf("unexpected failures %v", loadErr.Failures)
+	}
+
+	assertSameTasks(t, "json", tasks, sampleTasks())
+
+	if failures := FailuresOf(err, good); !reflect.DeepEqual(failures, loadErr.Failures) {
+		t.Errorf("unexpected failures %v", failures)
+	}
+}
+
+func waitChanged(t *testing.T, name string, changed chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: no change was notified", name)
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "schedule.json")
+
+	jsonStore := NewJsonFile([]string{filename}, func(patterns []string) watcher.Watcher {
+		return watcher.New(time.Millisecond * 20)
+	})
+
+	sqliteFilename := filepath.Join(dir, "tasks.db")
+	sqliteStore, err := NewSqlite(sqliteFilename, time.Millisecond*20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqliteStore.Close()
+
+	otherSqliteStore, err := NewSqlite(sqliteFilename, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherSqliteStore.Close()
+
+	cases := []struct {
+		name      string
+		taskStore TaskStore
+		change    func() error
+	}{
+		{"json", jsonStore, func() error {
+			return logic.SaveScheduleToFile(filename, logic.NewSchedule(sampleTasks()))
+		}},
+		{"sqlite", sqliteStore, func() error {
+			return otherSqliteStore.Save(sampleTasks())
+		}},
+	}
+
+	for _, c := range cases {
+		changed := make(chan struct{}, 16)
+		c.taskStore.OnChanged(func() {
+			changed <- struct{}{}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		if err := c.taskStore.Watch(ctx); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if err := c.change(); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		waitChanged(t, c.name, changed)
+
+		loaded, err := c.taskStore.Load()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		assertSameTasks(t, c.name, loaded, sampleTasks())
+
+		cancel()
+		c.taskStore.Wait()
+	}
+
+	memoryStore := NewMemory(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := memoryStore.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	memoryStore.Wait()
+}
@@ -0,0 +1,41 @@
+package textmap
+
+import (
+	"math"
+	"time"
+)
+
+func FormatDuration(tm TextMap, d time.Duration, roundUp bool) string {
+	if d < 0 {
+		d = -d
+	}
+
+	count := func(unit time.Duration) int {
+		if roundUp {
+			return int(math.Ceil(float64(d) / float64(unit)))
+		}
+
+		return int(math.Floor(float64(d) / float64(unit)))
+	}
+
+	if seconds := count(time.Second); seconds < 60 {
+		return tm.Of("DURATION_SECONDS").Set("seconds", seconds).String()
+
+	} else if minutes := count(time.Minute); minutes < 60 {
+		return tm.Of("DURATION_MINUTES").Set("minutes", minutes).String()
+
+	} else if minutes < 24*60 {
+		if minutes%60 == 0 {
+			return tm.Of("DURATION_HOURS").Set("hours", minutes/60).String()
+		}
+
+		return tm.Of("DURATION_HOURS_MINUTES").
+			Set("hours", minutes/60).
+			Set("minutes", minutes%60).
+			String()
+
+	} else {
+		days := int(math.Max(1, math.Round(float64(d)/float64(time.Hour*24))))
+		return tm.Of("DURATION_DAYS").Set("days", days).String()
+	}
+}
@@ -0,0 +1,42 @@
+package textmap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedTextsHaveNoMissingKeys(t *testing.T) {
+	filenames, err := filepath.Glob(filepath.Join("..", "embed", "text.*.json"))
+	if err != nil {
+		t.Fatal(err)
+
+	} else if len(filenames) == 0 {
+		t.Fatal("no embedded texts were found")
+	}
+
+	textMap := New("en")
+
+	for _, filename := range filenames {
+		locale := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), "text."), ".json")
+
+		file, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = textMap.LoadJson(locale, file)
+		file.Close()
+
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+	}
+
+	for _, locale := range textMap.Locales() {
+		if missingKeys := textMap.MissingKeys(locale); 0 < len(missingKeys) {
+			t.Errorf("text.%s.json: missing keys: %s", locale, strings.Join(missingKeys, ", "))
+		}
+	}
+}
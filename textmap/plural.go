@@ -0,0 +1,69 @@
+package textmap
+
+import (
+	"math"
+	"strings"
+)
+
+type pluralRule func(number float64) string
+
+var pluralRules = map[string]pluralRule{
+	"ja": pluralOther,
+	"ko": pluralOther,
+	"zh": pluralOther,
+	"th": pluralOther,
+	"vi": pluralOther,
+	"id": pluralOther,
+	"fr": pluralZeroOrOne,
+	"pt": pluralZeroOrOne,
+	"ru": pluralSlavic,
+	"uk": pluralSlavic,
+}
+
+func pluralCategoryOf(locale string, number float64) string {
+	language, _, _ := strings.Cut(NormalizeLocale(locale), "-")
+
+	if rule, ok := pluralRules[language]; ok {
+		return rule(number)
+	}
+
+	return pluralOne(number)
+}
+
+func pluralOther(number float64) string {
+	return "other"
+}
+
+func pluralOne(number float64) string {
+	if number == 1 {
+		return "one"
+	}
+
+	return "other"
+}
+
+func pluralZeroOrOne(number float64) string {
+	if 0 <= number && number < 2 {
+		return "one"
+	}
+
+	return "other"
+}
+
+func pluralSlavic(number float64) string {
+	if number != math.Trunc(number) {
+		return "other"
+	}
+
+	mod10 := int64(math.Abs(number)) % 10
+	mod100 := int64(math.Abs(number)) % 100
+
+	if mod10 == 1 && mod100 != 11 {
+		return "one"
+
+	} else if 2 <= mod10 && mod10 <= 4 && (mod100 < 12 || 14 < mod100) {
+		return "few"
+	}
+
+	return "many"
+}
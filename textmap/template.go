@@ -0,0 +1,379 @@
+package textmap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_TIME_LAYOUT = "15:04"
+
+type template struct {
+	nodes []templateNode
+}
+
+type templateNode interface {
+	render(builder *strings.Builder, context *renderContext)
+}
+
+type renderContext struct {
+	textMap TextMap
+	locale  string
+	values  map[string]any
+	number  string
+}
+
+type textNode string
+
+type numberSignNode struct{}
+
+type placeholderNode struct {
+	name     string
+	kind     string
+	argument string
+	branches map[string]*template
+	source   string
+}
+
+type TemplateError struct {
+	Offset int
+	Reason string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Reason)
+}
+
+type templateParser struct {
+	source string
+	offset int
+}
+
+func parseTemplate(source string) (*template, error) {
+	parser := &templateParser{source: source}
+
+	ret, err := parser.parseNodes(false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (p *templateParser) fail(format string, args ...any) error {
+	return &TemplateError{Offset: p.offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *templateParser) rest() string {
+	return p.source[p.offset:]
+}
+
+func (p *templateParser) skipSpaces() {
+	for p.offset < len(p.source) && strings.ContainsRune(" \t\r\n", rune(p.source[p.offset])) {
+		p.offset++
+	}
+}
+
+func (p *templateParser) readUntil(delimiters ...string) string {
+	begin := p.offset
+
+	for p.offset < len(p.source) {
+		for _, delimiter := range delimiters {
+			if strings.HasPrefix(p.rest(), delimiter) {
+				return p.source[begin:p.offset]
+			}
+		}
+
+		p.offset++
+	}
+
+	return p.source[begin:]
+}
+
+func (p *templateParser) parseNodes(inBranch bool, inPlural bool) (*template, error) {
+	ret := new(template)
+	var text strings.Builder
+
+	flush := func() {
+		if 0 < text.Len() {
+			ret.nodes = append(ret.nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for {
+		rest := p.rest()
+
+		if rest == "" {
+			if inBranch {
+				return nil, p.fail("unterminated branch")
+			}
+
+			flush()
+			return ret, nil
+
+		} else if strings.HasPrefix(rest, "{{") {
+			flush()
+
+			node, err := p.parsePlaceholder(inPlural)
+			if err != nil {
+				return nil, err
+			}
+
+			ret.nodes = append(ret.nodes, node)
+
+		} else if inBranch && rest[0] == '}' {
+			flush()
+			return ret, nil
+
+		} else if !inBranch && strings.HasPrefix(rest, "}}") {
+			return nil, p.fail(`unexpected "}}"`)
+
+		} else if inPlural && rest[0] == '#' {
+			flush()
+			ret.nodes = append(ret.nodes, numberSignNode{})
+			p.offset++
+
+		} else {
+			text.WriteByte(rest[0])
+			p.offset++
+		}
+	}
+}
+
+func (p *templateParser) parsePlaceholder(inPlural bool) (templateNode, error) {
+	begin := p.offset
+	p.offset += len("{{")
+
+	ret := new(placeholderNode)
+	ret.name = strings.TrimSpace(p.readUntil(",", "}}"))
+
+	if p.rest() == "" {
+		return nil, p.fail("placeholder is not closed")
+
+	} else if ret.name == "" {
+		return nil, p.fail("placeholder name is empty")
+	}
+
+	if strings.HasPrefix(p.rest(), ",") {
+		p.offset++
+
+		typeSpec := p.readUntil(",", "}}")
+		ret.kind, ret.argument, _ = strings.Cut(strings.TrimSpace(typeSpec), ":")
+
+		if ret.kind == "time" && strings.HasPrefix(p.rest(), ",") {
+			ret.argument += p.readUntil("}}")
+		}
+
+		ret.argument = strings.TrimSpace(ret.argument)
+
+		switch ret.kind {
+		case "number", "duration":
+			if ret.argument != "" {
+				return nil, p.fail(`type "%s" takes no argument`, ret.kind)
+			}
+
+		case "time":
+			if ret.argument == "" {
+				ret.argument = DEFAULT_TIME_LAYOUT
+			}
+
+		case "plural", "select":
+			if err := p.parseBranches(ret); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, p.fail(`unknown placeholder type "%s"`, ret.kind)
+		}
+	}
+
+	if !strings.HasPrefix(p.rest(), "}}") {
+		return nil, p.fail(`placeholder "%s" is not closed`, ret.name)
+	}
+
+	p.offset += len("}}")
+	ret.source = p.source[begin:p.offset]
+
+	return ret, nil
+}
+
+func (p *templateParser) parseBranches(node *placeholderNode) error {
+	node.branches = make(map[string]*template)
+
+	if !strings.HasPrefix(p.rest(), ",") {
+		return p.fail(`%s "%s" has no branches`, node.kind, node.name)
+	}
+
+	p.offset++
+
+	for {
+		p.skipSpaces()
+
+		if p.rest() == "" || strings.HasPrefix(p.rest(), "}}") {
+			break
+		}
+
+		selector := strings.TrimSpace(p.readUntil(" ", "\t", "\r", "\n", "{", "}"))
+		if selector == "" {
+			return p.fail("branch selector is empty")
+		}
+
+		if _, ok := node.branches[selector]; ok {
+			return p.fail(`duplicate branch "%s"`, selector)
+		}
+
+		p.skipSpaces()
+
+		if !strings.HasPrefix(p.rest(), "{") {
+			return p.fail(`branch "%s" has no body`, selector)
+		}
+
+		p.offset++
+
+		branch, err := p.parseNodes(true, node.kind == "plural")
+		if err != nil {
+			return err
+		}
+
+		p.offset++
+		node.branches[selector] = branch
+	}
+
+	if _, ok := node.branches["other"]; !ok {
+		return p.fail(`%s "%s" has no "other" branch`, node.kind, node.name)
+	}
+
+	return nil
+}
+
+func (t *template) render(builder *strings.Builder, context *renderContext) {
+	for _, node := range t.nodes {
+		node.render(builder, context)
+	}
+}
+
+func (t *template) placeholders(names map[string]bool) {
+	for _, node := range t.nodes {
+		if placeholder, ok := node.(*placeholderNode); ok {
+			names[placeholder.name] = true
+
+			for _, branch := range placeholder.branches {
+				branch.placeholders(names)
+			}
+		}
+	}
+}
+
+func (t *template) placeholderNames() []string {
+	names := make(map[string]bool)
+	t.placeholders(names)
+
+	ret := []string{}
+	for name := range names {
+		ret = append(ret, name)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func (n textNode) render(builder *strings.Builder, context *renderContext) {
+	builder.WriteString(string(n))
+}
+
+func (n numberSignNode) render(builder *strings.Builder, context *renderContext) {
+	builder.WriteString(context.number)
+}
+
+func (n *placeholderNode) render(builder *strings.Builder, context *renderContext) {
+	value, ok := context.values[n.name]
+	if !ok {
+		builder.WriteString(n.source)
+		return
+	}
+
+	switch n.kind {
+	case "time":
+		if t, ok := value.(time.Time); ok {
+			builder.WriteString(t.Format(n.argument))
+			return
+		}
+
+	case "duration":
+		if d, ok := value.(time.Duration); ok && context.textMap != nil {
+			builder.WriteString(FormatDuration(context.textMap, d, false))
+			return
+		}
+
+	case "plural":
+		if number, ok := numberOf(value); ok {
+			branch := n.branches["="+formatNumber(number)]
+			if branch == nil {
+				branch = n.branches[pluralCategoryOf(context.locale, number)]
+			}
+			if branch == nil {
+				branch = n.branches["other"]
+			}
+
+			outer := context.number
+			context.number = formatNumber(number)
+			branch.render(builder, context)
+			context.number = outer
+			return
+		}
+
+	case "select":
+		branch := n.branches[fmt.Sprint(value)]
+		if branch == nil {
+			branch = n.branches["other"]
+		}
+
+		branch.render(builder, context)
+		return
+	}
+
+	if number, ok := numberOf(value); ok {
+		builder.WriteString(formatNumber(number))
+	} else {
+		builder.WriteString(fmt.Sprint(value))
+	}
+}
+
+func numberOf(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -0,0 +1,122 @@
+package textmap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testTexts = map[string]string{
+	"en": `{
+		"DURATION_SECONDS": "{{seconds}}s",
+		"DURATION_MINUTES": "{{minutes}}m",
+		"DURATION_HOURS": "{{hours}}h",
+		"DURATION_HOURS_MINUTES": "{{hours}}h {{minutes}}m",
+		"DURATION_DAYS": "{{days, plural, one {# day} other {# days}}}",
+		"TEXT": "{{template}}"
+	}`,
+	"ja": `{
+		"DURATION_SECONDS": "{{seconds}}秒",
+		"DURATION_MINUTES": "{{minutes}}分",
+		"DURATION_HOURS": "{{hours}}時間",
+		"DURATION_HOURS_MINUTES": "{{hours}}時間{{minutes}}分",
+		"DURATION_DAYS": "{{days}}日",
+		"TEXT": "{{template}}"
+	}`,
+	"ru": `{
+		"TEXT": "{{template}}"
+	}`,
+}
+
+func newTestTextMap(t *testing.T, locale string) TextMap {
+	t.Helper()
+
+	textMap := New("en")
+
+	for textLocale, texts := range testTexts {
+		if err := textMap.LoadJson(textLocale, strings.NewReader(texts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	textMap.SetLocale(locale)
+
+	return textMap
+}
+
+func TestTemplateRender(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 5, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		locale   string
+		template string
+		values   map[string]any
+		expected string
+	}{
+		{"plain text", "en", "hello", nil, "hello"},
+		{"placeholder", "en", "hello {{name}}", map[string]any{"name": "world"}, "hello world"},
+		{"missing value", "en", "hello {{name}}", nil, "hello {{name}}"},
+		{"number", "en", "{{n, number}}", map[string]any{"n": 1.5}, "1.5"},
+		{"default time", "en", "at {{at, time}}", map[string]any{"at": at}, "at 09:05"},
+		{"time layout", "en", "{{at, time:2006-01-02 15:04}}", map[string]any{"at": at}, "2024-01-02 09:05"},
+		{"seconds", "en", "{{d, duration}}", map[string]any{"d": time.Second * 10}, "10s"},
+		{"minutes", "en", "{{d, duration}}", map[string]any{"d": time.Minute * 20}, "20m"},
+		{"hours and minutes", "en", "{{d, duration}}", map[string]any{"d": time.Minute * 90}, "1h 30m"},
+		{"whole hours", "en", "{{d, duration}}", map[string]any{"d": time.Hour * 2}, "2h"},
+		{"one day", "en", "{{d, duration}}", map[string]any{"d": time.Hour * 24}, "1 day"},
+		{"days", "en", "{{d, duration}}", map[string]any{"d": time.Hour * 72}, "3 days"},
+		{"localized duration", "ja", "{{d, duration}}", map[string]any{"d": time.Minute * 90}, "1時間30分"},
+		{"plural one", "en", "{{n, plural, one {# task} other {# tasks}}}", map[string]any{"n": 1}, "1 task"},
+		{"plural other", "en", "{{n, plural, one {# task} other {# tasks}}}", map[string]any{"n": 2}, "2 tasks"},
+		{"plural exact", "en", "{{n, plural, =0 {no tasks} one {# task} other {# tasks}}}", map[string]any{"n": 0}, "no tasks"},
+		{"plural without one", "ja", "{{n, plural, one {one} other {#件}}}", map[string]any{"n": 1}, "1件"},
+		{"plural few", "ru", "{{n, plural, one {one} few {few} many {many} other {other}}}", map[string]any{"n": 3}, "few"},
+		{"plural many", "ru", "{{n, plural, one {one} few {few} many {many} other {other}}}", map[string]any{"n": 11}, "many"},
+		{"nested plural", "en", "{{n, plural, one {{{m, plural, one {#/#} other {#}}}} other {#}}}", map[string]any{"n": 1, "m": 2}, "2"},
+		{"select", "en", "{{kind, select, meeting {Meeting} other {Task}}}", map[string]any{"kind": "meeting"}, "Meeting"},
+		{"select other", "en", "{{kind, select, meeting {Meeting} other {Task}}}", map[string]any{"kind": "call"}, "Task"},
+		{"select with placeholder", "en", "{{kind, select, meeting {with {{who}}} other {alone}}}", map[string]any{"kind": "meeting", "who": "Bob"}, "with Bob"},
+	}
+
+	for _, c := range cases {
+		textMap := newTestTextMap(t, c.locale)
+
+		parsed, err := parseTemplate(c.template)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+
+		entry := TextEntry{textMap: textMap, locale: c.locale, template: parsed}
+		for key, value := range c.values {
+			entry = entry.Set(key, value)
+		}
+
+		if actual := entry.String(); actual != c.expected {
+			t.Errorf("%s: rendered %q, expected %q", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestTemplateParseErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+	}{
+		{"unclosed placeholder", "{{name"},
+		{"empty name", "{{}}"},
+		{"unknown type", "{{name, color}}"},
+		{"argument for number", "{{n, number:2}}"},
+		{"no other branch", "{{n, plural, one {#}}}"},
+		{"duplicate branch", "{{n, plural, one {#} one {#} other {#}}}"},
+		{"unterminated branch", "{{n, plural, other {#"},
+		{"stray braces", "text }}"},
+	}
+
+	for _, c := range cases {
+		if _, err := parseTemplate(c.template); err == nil {
+			t.Errorf("%s: parsed %q", c.name, c.template)
+		}
+	}
+}
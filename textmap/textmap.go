@@ -2,56 +2,298 @@ package textmap
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 )
 
 type TextMap interface {
-	LoadJson(reader io.Reader) error
+	LoadJson(locale string, reader io.Reader) error
+	Override(locale string, reader io.Reader) ([]string, error)
+	ResetOverrides()
+	SetLocale(locale string)
+	Locale() string
+	Locales() []string
+	MissingKeys(locale string) []string
 	Of(key string) TextEntry
 }
 
-type TextEntry string
+type TextEntry struct {
+	textMap  TextMap
+	locale   string
+	template *template
+	values   map[string]any
+}
 
 type _TextMap struct {
-	table map[string]string
+	mutex         sync.RWMutex
+	defaultLocale string
+	locale        string
+	tables        map[string]map[string]*template
+	overrides     map[string]map[string]*template
+}
+
+func New(defaultLocale string) TextMap {
+	ret := new(_TextMap)
+	ret.defaultLocale = NormalizeLocale(defaultLocale)
+	ret.locale = ret.defaultLocale
+	ret.tables = make(map[string]map[string]*template)
+	ret.overrides = make(map[string]map[string]*template)
+	return ret
 }
 
-func New() TextMap {
-	return new(_TextMap)
+func NormalizeLocale(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "_", "-")
+
+	parts := strings.Split(locale, "-")
+	for index := range parts {
+		if index == 0 {
+			parts[index] = strings.ToLower(parts[index])
+		} else if len(parts[index]) == 2 {
+			parts[index] = strings.ToUpper(parts[index])
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+func FallbackChain(locale string, defaultLocale string) []string {
+	ret := []string{}
+
+	parts := strings.Split(NormalizeLocale(locale), "-")
+	for length := len(parts); 0 < length; length-- {
+		if candidate := strings.Join(parts[:length], "-"); candidate != "" {
+			ret = append(ret, candidate)
+		}
+	}
+
+	if defaultLocale = NormalizeLocale(defaultLocale); len(ret) == 0 || ret[len(ret)-1] != defaultLocale {
+		ret = append(ret, defaultLocale)
+	}
+
+	return ret
 }
 
-func (tm *_TextMap) LoadJson(reader io.Reader) error {
-	table := make(map[string]string)
+func (tm *_TextMap) LoadJson(locale string, reader io.Reader) error {
+	sources := make(map[string]string)
+
+	if err := json.NewDecoder(reader).Decode(&sources); err != nil {
+		return fmt.Errorf("%s: %w", locale, err)
+	}
+
+	table := make(map[string]*template)
+
+	for _, key := range sortedKeysOf(sources) {
+		parsed, err := parseTemplate(sources[key])
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", locale, key, err)
+		}
 
-	if err := json.NewDecoder(reader).Decode(&table); err != nil {
-		return err
+		table[key] = parsed
 	}
 
-	tm.table = table
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.tables[NormalizeLocale(locale)] = table
 
 	return nil
 }
 
+func (tm *_TextMap) Override(locale string, reader io.Reader) ([]string, error) {
+	sources := make(map[string]string)
+
+	if err := json.NewDecoder(reader).Decode(&sources); err != nil {
+		return nil, fmt.Errorf("%s: %w", locale, err)
+	}
+
+	locale = NormalizeLocale(locale)
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	warnings := []string{}
+	errs := []error{}
+	table := make(map[string]*template)
+	knownKeys := tm.allKeys()
+
+	for _, key := range sortedKeysOf(sources) {
+		if !knownKeys[key] {
+			warnings = append(warnings, fmt.Sprintf(`%s: unknown key "%s"`, locale, key))
+			continue
+		}
+
+		parsed, err := parseTemplate(sources[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", locale, key, err))
+			continue
+		}
+
+		expected := tm.embeddedOf(locale, key).placeholderNames()
+		if actual := parsed.placeholderNames(); strings.Join(actual, ",") != strings.Join(expected, ",") {
+			errs = append(errs, fmt.Errorf("%s: %s: placeholders [%s] do not match [%s]", locale, key, strings.Join(actual, ", "), strings.Join(expected, ", ")))
+			continue
+		}
+
+		table[key] = parsed
+	}
+
+	if 0 < len(errs) {
+		return warnings, errors.Join(errs...)
+	}
+
+	if tm.overrides[locale] == nil {
+		tm.overrides[locale] = make(map[string]*template)
+	}
+
+	for key, parsed := range table {
+		tm.overrides[locale][key] = parsed
+	}
+
+	return warnings, nil
+}
+
+func (tm *_TextMap) ResetOverrides() {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.overrides = make(map[string]map[string]*template)
+}
+
+func (tm *_TextMap) SetLocale(locale string) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.locale = NormalizeLocale(locale)
+}
+
+func (tm *_TextMap) Locale() string {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	for _, candidate := range FallbackChain(tm.locale, tm.defaultLocale) {
+		if _, ok := tm.overrides[candidate]; ok {
+			return candidate
+
+		} else if _, ok := tm.tables[candidate]; ok {
+			return candidate
+		}
+	}
+
+	return tm.defaultLocale
+}
+
+func (tm *_TextMap) Locales() []string {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	ret := []string{}
+
+	for locale := range tm.tables {
+		ret = append(ret, locale)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func (tm *_TextMap) MissingKeys(locale string) []string {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	ret := []string{}
+	table := tm.tables[NormalizeLocale(locale)]
+
+	for key := range tm.allKeys() {
+		if _, ok := table[key]; !ok {
+			ret = append(ret, key)
+		}
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
 func (tm *_TextMap) Of(key string) TextEntry {
-	return TextEntry(tm.table[key])
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	for _, candidate := range FallbackChain(tm.locale, tm.defaultLocale) {
+		if parsed, ok := tm.overrides[candidate][key]; ok {
+			return TextEntry{textMap: tm, locale: candidate, template: parsed}
+
+		} else if parsed, ok := tm.tables[candidate][key]; ok {
+			return TextEntry{textMap: tm, locale: candidate, template: parsed}
+		}
+	}
+
+	return TextEntry{textMap: tm, locale: tm.defaultLocale, template: new(template)}
 }
 
-func (te TextEntry) _set(key, format string, value any) TextEntry {
-	from := fmt.Sprintf("{{%s}}", key)
-	to := fmt.Sprintf(format, value)
-	return TextEntry(strings.ReplaceAll(string(te), from, to))
+func (tm *_TextMap) embeddedOf(locale string, key string) *template {
+	for _, candidate := range FallbackChain(locale, tm.defaultLocale) {
+		if parsed, ok := tm.tables[candidate][key]; ok {
+			return parsed
+		}
+	}
+
+	return new(template)
+}
+
+func (tm *_TextMap) allKeys() map[string]bool {
+	ret := make(map[string]bool)
+
+	for _, table := range tm.tables {
+		for key := range table {
+			ret[key] = true
+		}
+	}
+
+	return ret
 }
 
 func (te TextEntry) Set(key string, value any) TextEntry {
-	return te._set(key, "%s", value)
+	values := make(map[string]any, len(te.values)+1)
+	for k, v := range te.values {
+		values[k] = v
+	}
+	values[key] = value
+
+	te.values = values
+
+	return te
 }
 
 func (te TextEntry) SetInt(key string, value any) TextEntry {
-	return te._set(key, "%d", value)
+	return te.Set(key, value)
 }
 
 func (te TextEntry) String() string {
-	return string(te)
+	if te.template == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	te.template.render(&builder, &renderContext{textMap: te.textMap, locale: te.locale, values: te.values})
+
+	return builder.String()
+}
+
+func sortedKeysOf[V any](m map[string]V) []string {
+	ret := []string{}
+
+	for key := range m {
+		ret = append(ret, key)
+	}
+
+	sort.Strings(ret)
+
+	return ret
 }
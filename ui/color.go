@@ -0,0 +1,11 @@
+package ui
+
+import (
+	"time-meter/color"
+
+	"github.com/cwchiu/go-winapi"
+)
+
+func colorRefOf(c color.Color) winapi.COLORREF {
+	return winapi.RGB(int32(c.R), int32(c.G), int32(c.B))
+}
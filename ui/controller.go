@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"sync"
 	"syscall"
 	"time"
 	"time-meter/logic"
@@ -15,6 +16,7 @@ import (
 type Controller interface {
 	SetTextMap(textMap textmap.TextMap)
 	SetSettings(settings *setting.Settings)
+	UpdateSettings(settings setting.Settings)
 	SetTasks(tasks []logic.Task)
 	SetErrorMessage(message string)
 	OnPopupMenuCommand(handler PopupMenuCommandHandler)
@@ -28,9 +30,11 @@ type Controller interface {
 type PopupMenuCommandHandler func(menuId MenuId)
 
 type controller struct {
+	mutex                   sync.Mutex
 	textMap                 textmap.TextMap
 	settings                *setting.Settings
-	tasks                   []logic.Task
+	pendingSettings         *setting.Settings
+	taskIndex               *logic.TaskIndex
 	popupMenuCommandHandler PopupMenuCommandHandler
 	meterWindow             *MeterWindow
 	tipWindow               *TipWindow
@@ -44,9 +48,20 @@ type MenuId int16
 const (
 	MID_ZERO MenuId = iota
 	MID_EDIT_SCHEDULE
+	MID_EDIT_SETTINGS
+	MID_SHIFT_5_MINUTES
+	MID_SHIFT_10_MINUTES
+	MID_SHIFT_15_MINUTES
 	MID_QUIT
 )
 
+const (
+	MID_THEME_FIRST   MenuId = 0x1000
+	MID_THEME_LAST    MenuId = 0x1fff
+	MID_PROFILE_FIRST MenuId = 0x2000
+	MID_PROFILE_LAST  MenuId = 0x2fff
+)
+
 func NewController() Controller {
 	ret := new(controller)
 	ret.meterWindow = new(MeterWindow)
@@ -70,11 +85,18 @@ func (c *controller) SetSettings(settings *setting.Settings) {
 	c.tipRenderer.settings = settings
 }
 
+func (c *controller) UpdateSettings(settings setting.Settings) {
+	c.mutex.Lock()
+	c.pendingSettings = &settings
+	c.mutex.Unlock()
+
+	c.meterWindow.RequestUpdateSettings()
+}
+
 func (c *controller) SetTasks(tasks []logic.Task) {
-	c.tasks = []logic.Task{}
-	c.tasks = append(c.tasks, tasks...)
+	c.taskIndex = logic.NewTaskIndex(tasks)
 
-	c.meterRenderer.tasks = c.tasks
+	c.meterRenderer.taskIndex = c.taskIndex
 }
 
 func (c *controller) SetErrorMessage(message string) {
@@ -109,13 +131,10 @@ func (c *controller) Initialize() error {
 		return err
 	}
 
-	if err := c.contextMenu.Initialize(); err != nil {
+	if err := c.buildContextMenu(); err != nil {
 		return err
 	}
 
-	c.contextMenu.AppendStringItem(MID_EDIT_SCHEDULE, c.textMap.Of("VERB_EDIT_SCHEDULE").String())
-	c.contextMenu.AppendStringItem(MID_QUIT, c.textMap.Of("VERB_QUIT").String())
-
 	c.meterWindow.onPaint = func() {
 		c.meterRenderer.width = c.meterWindow.bound.Width()
 		c.meterRenderer.height = c.meterWindow.bound.Height()
@@ -130,12 +149,7 @@ func (c *controller) Initialize() error {
 		totalDuration := c.settings.FutureDuration + c.settings.PastDuration
 		focusAt := time.Now().Add(-c.settings.PastDuration + time.Duration(focusRatio*float64(totalDuration)))
 
-		var focusTasks []logic.Task
-		for _, task := range c.tasks {
-			if task.OverlapWith(focusAt, focusAt) {
-				focusTasks = append(focusTasks, task)
-			}
-		}
+		focusTasks := c.taskIndex.At(focusAt)
 
 		c.tipRenderer.tasks = focusTasks
 
@@ -154,6 +168,10 @@ func (c *controller) Initialize() error {
 		c.tipWindow.Update()
 	}
 
+	c.meterWindow.onUpdateChart = func() {
+		winapi.InvalidateRect(c.tipWindow.hWnd, nil, true)
+	}
+
 	c.meterWindow.onMouseEnter = func() {
 		c.tipWindow.Show()
 	}
@@ -163,6 +181,8 @@ func (c *controller) Initialize() error {
 	}
 
 	c.meterWindow.onMouseRightClick = func() {
+		c.contextMenu.Finalize()
+		c.buildContextMenu()
 		c.contextMenu.Popup(c.meterWindow.hWnd)
 	}
 
@@ -172,6 +192,17 @@ func (c *controller) Initialize() error {
 		}
 	}
 
+	c.meterWindow.onUpdateSettings = func() {
+		c.mutex.Lock()
+		pendingSettings := c.pendingSettings
+		c.pendingSettings = nil
+		c.mutex.Unlock()
+
+		if pendingSettings != nil {
+			c.applySettings(*pendingSettings)
+		}
+	}
+
 	c.tipWindow.onPaint = func() {
 		c.tipRenderer.Draw(c.tipWindow.hWnd)
 	}
@@ -179,6 +210,74 @@ func (c *controller) Initialize() error {
 	return nil
 }
 
+func (c *controller) buildContextMenu() error {
+	if err := c.contextMenu.Initialize(); err != nil {
+		return err
+	}
+
+	c.contextMenu.AppendStringItem(MID_EDIT_SCHEDULE, c.textMap.Of("VERB_EDIT_SCHEDULE").String())
+	c.contextMenu.AppendStringItem(MID_EDIT_SETTINGS, c.textMap.Of("VERB_EDIT_SETTINGS").String())
+
+	shiftMenu := new(PopupMenu)
+	if err := shiftMenu.Initialize(); err != nil {
+		return err
+	}
+
+	shiftMenu.AppendStringItem(MID_SHIFT_5_MINUTES, c.textMap.Of("VERB_SHIFT_MINUTES").Set("minutes", 5).String())
+	shiftMenu.AppendStringItem(MID_SHIFT_10_MINUTES, c.textMap.Of("VERB_SHIFT_MINUTES").Set("minutes", 10).String())
+	shiftMenu.AppendStringItem(MID_SHIFT_15_MINUTES, c.textMap.Of("VERB_SHIFT_MINUTES").Set("minutes", 15).String())
+	c.contextMenu.AppendSubMenu(c.textMap.Of("NOUN_RUNNING_LATE").String(), shiftMenu)
+
+	if themeNames := c.settings.ThemeNames(); 0 < len(themeNames) {
+		themeMenu := new(PopupMenu)
+		if err := themeMenu.Initialize(); err != nil {
+			return err
+		}
+
+		c.appendSelectionItems(themeMenu, MID_THEME_FIRST, themeNames, c.settings.Theme)
+		c.contextMenu.AppendSubMenu(c.textMap.Of("NOUN_THEME").String(), themeMenu)
+	}
+
+	if profileNames := c.settings.ProfileNames(); 0 < len(profileNames) {
+		profileMenu := new(PopupMenu)
+		if err := profileMenu.Initialize(); err != nil {
+			return err
+		}
+
+		c.appendSelectionItems(profileMenu, MID_PROFILE_FIRST, profileNames, c.settings.Profile)
+		c.contextMenu.AppendSubMenu(c.textMap.Of("NOUN_PROFILE").String(), profileMenu)
+	}
+
+	c.contextMenu.AppendSeparator()
+	c.contextMenu.AppendStringItem(MID_QUIT, c.textMap.Of("VERB_QUIT").String())
+
+	return nil
+}
+
+func (c *controller) appendSelectionItems(menu *PopupMenu, firstMenuId MenuId, names []string, activeName string) {
+	menu.AppendCheckItem(firstMenuId, c.textMap.Of("NOUN_NONE").String(), activeName == "")
+
+	for index, name := range names {
+		menu.AppendCheckItem(firstMenuId+MenuId(index+1), name, name == activeName)
+	}
+}
+
+func (c *controller) applySettings(settings setting.Settings) {
+	*c.settings = settings
+
+	c.tipRenderer.Finalize()
+	c.meterRenderer.Finalize()
+
+	c.meterRenderer.Initialize()
+	c.tipRenderer.Initialize()
+
+	c.meterWindow.updateLayout()
+	c.meterWindow.updateOpacity()
+
+	winapi.InvalidateRect(c.meterWindow.hWnd, nil, true)
+	winapi.InvalidateRect(c.tipWindow.hWnd, nil, true)
+}
+
 func (c *controller) Run() {
 	c.meterWindow.Show()
 
@@ -4,6 +4,7 @@ import (
 	"time"
 	"time-meter/logic"
 	"time-meter/setting"
+	winapi2 "time-meter/winapi"
 	"time-meter/wrapped"
 
 	"github.com/cwchiu/go-winapi"
@@ -11,28 +12,28 @@ import (
 
 type MeterRenderer struct {
 	settings        *setting.Settings
-	tasks           []logic.Task
+	taskIndex       *logic.TaskIndex
 	width           int32
 	height          int32
 	backgroundBrush winapi.HBRUSH
-	headPen         winapi.HPEN
-	hourPen         winapi.HPEN
+	headBrush       winapi.HBRUSH
+	hourBrush       winapi.HBRUSH
 	chartBrush      winapi.HBRUSH
 }
 
 func (mr *MeterRenderer) Initialize() error {
-	mr.backgroundBrush = winapi.CreateSolidBrush(mr.settings.BackgroundColor)
-	mr.headPen = winapi.CreatePen(winapi.PS_SOLID, 1, mr.settings.MainScaleColor)
-	mr.hourPen = winapi.CreatePen(winapi.PS_SOLID, 1, mr.settings.SubScalesColor)
-	mr.chartBrush = winapi.CreateSolidBrush(mr.settings.ChartColor)
+	mr.backgroundBrush = winapi.CreateSolidBrush(colorRefOf(mr.settings.BackgroundColor))
+	mr.headBrush = winapi.CreateSolidBrush(colorRefOf(mr.settings.MainScaleColor))
+	mr.hourBrush = winapi.CreateSolidBrush(colorRefOf(mr.settings.SubScalesColor))
+	mr.chartBrush = winapi.CreateSolidBrush(colorRefOf(mr.settings.ChartColor))
 
 	return nil
 }
 
 func (mr *MeterRenderer) Finalize() error {
 	winapi.DeleteObject(winapi.HGDIOBJ(mr.backgroundBrush))
-	winapi.DeleteObject(winapi.HGDIOBJ(mr.headPen))
-	winapi.DeleteObject(winapi.HGDIOBJ(mr.hourPen))
+	winapi.DeleteObject(winapi.HGDIOBJ(mr.headBrush))
+	winapi.DeleteObject(winapi.HGDIOBJ(mr.hourBrush))
 	winapi.DeleteObject(winapi.HGDIOBJ(mr.chartBrush))
 
 	return nil
@@ -49,17 +50,26 @@ func (mr *MeterRenderer) Draw(hWnd winapi.HWND) {
 	winapi.GetClientRect(hWnd, clientRect.Unwrap())
 	winapi.FillRect(backDc, clientRect.Unwrap(), mr.backgroundBrush)
 
-	mr.drawAllCharts(backDc,
-		mr.tasks,
-		time.Now(),
-		mr.settings.FutureDuration,
-		mr.settings.PastDuration,
-	)
+	if mr.settings.SubScalesVisible {
+		mr.drawSubScaleLines(backDc,
+			mr.settings.FutureDuration,
+			mr.settings.PastDuration,
+			mr.settings.ScaleInterval,
+		)
+	}
+
+	if mr.settings.ChartVisible {
+		mr.drawAllCharts(backDc,
+			mr.taskIndex,
+			time.Now(),
+			mr.settings.FutureDuration,
+			mr.settings.PastDuration,
+		)
+	}
 
-	mr.drawAllScaleLines(backDc,
+	mr.drawHeadLine(backDc,
 		mr.settings.FutureDuration,
 		mr.settings.PastDuration,
-		mr.settings.ScaleInterval,
 	)
 
 	backBuffer.end()
@@ -67,87 +77,80 @@ func (mr *MeterRenderer) Draw(hWnd winapi.HWND) {
 	winapi.EndPaint(hWnd, &paint)
 }
 
-func (mr *MeterRenderer) drawAllCharts(hdc winapi.HDC, tasks []logic.Task, now time.Time, futureDuration, pastDuration time.Duration) {
+func (mr *MeterRenderer) drawAllCharts(hdc winapi.HDC, taskIndex *logic.TaskIndex, now time.Time, futureDuration, pastDuration time.Duration) {
 	chartBeginAt := now.Add(-pastDuration)
 	chartEndAt := now.Add(futureDuration)
 	totalSeconds := int32((futureDuration + pastDuration) / time.Second)
 
-	tracks := [][]logic.Task{}
-
-	for _, task := range tasks {
-		if !task.OverlapWith(chartBeginAt, chartEndAt) {
-			continue
-		}
-
-		found := false
-
-		for index := range tracks {
-			if !mr.isTaskConflict(tracks[index], task) {
-				tracks[index] = append(tracks[index], task)
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			tracks = append(tracks, []logic.Task{task})
-		}
-	}
-
-	if len(tracks) == 0 {
-		return
-	}
-
-	trackWidth := int(mr.width) / len(tracks)
-
-	for trackIndex, track := range tracks {
-		for _, task := range track {
-			var rect wrapped.RECT
-			rect.Left = int32(trackIndex*trackWidth) + 1
-			rect.Right = rect.Left + int32(trackWidth) - 2
-			rect.Top = mr.height - mr.height*int32(task.EndAt.Sub(chartBeginAt)/time.Second)/totalSeconds + 1
-			rect.Bottom = mr.height - mr.height*int32(task.BeginAt.Sub(chartBeginAt)/time.Second)/totalSeconds - 1
-			mr.drawChart(hdc, &rect)
-		}
-	}
-}
-
-func (mr *MeterRenderer) isTaskConflict(tasks []logic.Task, desiredTask logic.Task) bool {
-	for _, task := range tasks {
-		if task.OverlapWith(desiredTask.BeginAt, desiredTask.EndAt) {
-			return true
-		}
+	for _, layout := range logic.LayoutTasks(taskIndex.Between(chartBeginAt, chartEndAt)) {
+		var rect wrapped.RECT
+		rect.Left = mr.width*int32(layout.Column)/int32(layout.Columns) + 1
+		rect.Right = mr.width*int32(layout.Column+layout.Span)/int32(layout.Columns) - 1
+		rect.Top = mr.height - mr.height*int32(layout.Task.EndAt.Sub(chartBeginAt)/time.Second)/totalSeconds + 1
+		rect.Bottom = mr.height - mr.height*int32(layout.Task.BeginAt.Sub(chartBeginAt)/time.Second)/totalSeconds - 1
+		mr.drawChart(hdc, &rect)
 	}
-	return false
 }
 
 func (mr *MeterRenderer) drawChart(hdc winapi.HDC, rect *wrapped.RECT) {
-	winapi.FillRect(hdc, rect.Unwrap(), mr.chartBrush)
+	mr.fillRect(hdc, rect, mr.chartBrush, mr.settings.ChartColor.A)
 }
 
-func (mr *MeterRenderer) drawAllScaleLines(hdc winapi.HDC, futureDuration, pastDuration, interval time.Duration) {
+func (mr *MeterRenderer) drawSubScaleLines(hdc winapi.HDC, futureDuration, pastDuration, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
 	offset := futureDuration
 	totalDuration := futureDuration + pastDuration
 	totalSeconds := int32(totalDuration / time.Second)
 
-	winapi.SelectObject(hdc, winapi.HGDIOBJ(mr.hourPen))
-
 	for interval < offset {
 		offset -= interval
 	}
 
 	for offset < totalDuration {
 		if offset != futureDuration {
-			mr.drawScaleLine(hdc, mr.height*int32(offset/time.Second)/totalSeconds)
+			mr.drawScaleLine(hdc, mr.height*int32(offset/time.Second)/totalSeconds, mr.hourBrush, mr.settings.SubScalesColor.A)
 		}
 		offset += interval
 	}
+}
+
+func (mr *MeterRenderer) drawHeadLine(hdc winapi.HDC, futureDuration, pastDuration time.Duration) {
+	totalSeconds := int32((futureDuration + pastDuration) / time.Second)
+	mr.drawScaleLine(hdc, mr.height*int32(futureDuration/time.Second)/totalSeconds, mr.headBrush, mr.settings.MainScaleColor.A)
+}
 
-	winapi.SelectObject(hdc, winapi.HGDIOBJ(mr.headPen))
-	mr.drawScaleLine(hdc, mr.height*int32(futureDuration/time.Second)/totalSeconds)
+func (mr *MeterRenderer) drawScaleLine(hdc winapi.HDC, y int32, brush winapi.HBRUSH, alpha byte) {
+	rect := wrapped.RECT{Left: 0, Top: y, Right: mr.width, Bottom: y + 1}
+	mr.fillRect(hdc, &rect, brush, alpha)
 }
 
-func (mr *MeterRenderer) drawScaleLine(hdc winapi.HDC, y int32) {
-	winapi.MoveToEx(hdc, 0, y, nil)
-	winapi.LineTo(hdc, mr.width, y)
+func (mr *MeterRenderer) fillRect(hdc winapi.HDC, rect *wrapped.RECT, brush winapi.HBRUSH, alpha byte) {
+	if alpha == 0 || rect.Width() <= 0 || rect.Height() <= 0 {
+		return
+	}
+
+	if alpha == 255 {
+		winapi.FillRect(hdc, rect.Unwrap(), brush)
+		return
+	}
+
+	layerRect := wrapped.RECT{Left: 0, Top: 0, Right: rect.Width(), Bottom: rect.Height()}
+
+	layerDc := winapi.CreateCompatibleDC(hdc)
+	layerBitmap := winapi2.CreateCompatibleBitmap(hdc, layerRect.Width(), layerRect.Height())
+	oldLayerBitmap := winapi.SelectObject(layerDc, winapi.HGDIOBJ(layerBitmap))
+
+	winapi.FillRect(layerDc, layerRect.Unwrap(), brush)
+
+	winapi2.AlphaBlend(
+		hdc, rect.Left, rect.Top, rect.Width(), rect.Height(),
+		layerDc, 0, 0, layerRect.Width(), layerRect.Height(),
+		winapi2.BLENDFUNCTION{BlendOp: winapi2.AC_SRC_OVER, SourceConstantAlpha: alpha})
+
+	winapi.SelectObject(layerDc, oldLayerBitmap)
+	winapi.DeleteDC(layerDc)
+	winapi.DeleteObject(winapi.HGDIOBJ(layerBitmap))
 }
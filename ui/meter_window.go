@@ -25,6 +25,8 @@ type MeterWindow struct {
 	onMouseLeave       util.EventHandler
 	onMouseRightClick  util.EventHandler
 	onPopupMenuCommand util.EventHandler
+	onUpdateSettings   util.EventHandler
+	onUpdateChart      util.EventHandler
 }
 
 const (
@@ -32,6 +34,10 @@ const (
 	EID_WATCH_MOUSE
 )
 
+const (
+	WM_UPDATE_SETTINGS = winapi.WM_APP + 1 + iota
+)
+
 func (mw *MeterWindow) Initialize() error {
 	hInstance := winapi.GetModuleHandle(nil)
 	windowClass := mw.createWindowClass(hInstance)
@@ -63,6 +69,10 @@ func (mw *MeterWindow) Show() {
 	winapi.SetTimer(mw.hWnd, EID_WATCH_MOUSE, 1000/30, 0)
 }
 
+func (mw *MeterWindow) RequestUpdateSettings() {
+	winapi.PostMessage(mw.hWnd, WM_UPDATE_SETTINGS, 0, 0)
+}
+
 func (mw *MeterWindow) createWindowClass(hInstance winapi.HINSTANCE) winapi.WNDCLASSEX {
 	var ret winapi.WNDCLASSEX
 
@@ -154,16 +164,25 @@ func (mw *MeterWindow) watchMouse() {
 		return
 	}
 
+	mw.updateOpacity()
+
 	if isHit {
-		winapi2.SetLayeredWindowAttributes(mw.hWnd, 0, 255, winapi2.LWA_ALPHA)
 		mw.onMouseEnter.Invoke()
 
 	} else {
-		winapi2.SetLayeredWindowAttributes(mw.hWnd, 0, mw.settings.MeterOpacity, winapi2.LWA_ALPHA)
 		mw.onMouseLeave.Invoke()
 	}
 }
 
+func (mw *MeterWindow) updateOpacity() {
+	if mw.bound.Contains(mw.lastCursorPos) {
+		winapi2.SetLayeredWindowAttributes(mw.hWnd, 0, 255, winapi2.LWA_ALPHA)
+
+	} else {
+		winapi2.SetLayeredWindowAttributes(mw.hWnd, 0, mw.settings.MeterOpacity, winapi2.LWA_ALPHA)
+	}
+}
+
 func (mw *MeterWindow) wndProc(hWnd winapi.HWND, msg uint32, wParam uintptr, lParam uintptr) uintptr {
 	switch msg {
 	case winapi.WM_PAINT:
@@ -194,11 +213,15 @@ func (mw *MeterWindow) wndProc(hWnd winapi.HWND, msg uint32, wParam uintptr, lPa
 		switch wParam {
 		case EID_UPDATE_CHART:
 			winapi.InvalidateRect(hWnd, nil, true)
+			mw.onUpdateChart.Invoke()
 
 		case EID_WATCH_MOUSE:
 			mw.watchMouse()
 		}
 
+	case WM_UPDATE_SETTINGS:
+		mw.onUpdateSettings.Invoke()
+
 	case winapi.WM_DESTROY:
 		winapi.PostQuitMessage(0)
 
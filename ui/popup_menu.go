@@ -27,6 +27,25 @@ func (pm *PopupMenu) AppendStringItem(menuId MenuId, title string) {
 	winapi.AppendMenu(pm.hMenu, winapi.MF_STRING, winapi.UINT_PTR(menuId), titlePtr)
 }
 
+func (pm *PopupMenu) AppendCheckItem(menuId MenuId, title string, checked bool) {
+	var flags winapi.UINT = winapi.MF_STRING
+	if checked {
+		flags |= winapi.MF_CHECKED
+	}
+
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	winapi.AppendMenu(pm.hMenu, flags, winapi.UINT_PTR(menuId), titlePtr)
+}
+
+func (pm *PopupMenu) AppendSubMenu(title string, subMenu *PopupMenu) {
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	winapi.AppendMenu(pm.hMenu, winapi.MF_STRING|winapi.MF_POPUP, winapi.UINT_PTR(subMenu.hMenu), titlePtr)
+}
+
+func (pm *PopupMenu) AppendSeparator() {
+	winapi.AppendMenu(pm.hMenu, winapi.MF_SEPARATOR, 0, nil)
+}
+
 func (pm *PopupMenu) Popup(hWnd winapi.HWND) {
 	var pos wrapped.POINT
 	winapi.GetCursorPos(pos.Unwrap())
@@ -1,7 +1,6 @@
 package ui
 
 import (
-	"math"
 	"syscall"
 	"time"
 	"time-meter/logic"
@@ -23,7 +22,7 @@ type TipRenderer struct {
 }
 
 func (tr *TipRenderer) Initialize() error {
-	tr.backgroundBrush = winapi.CreateSolidBrush(tr.settings.BackgroundColor)
+	tr.backgroundBrush = winapi.CreateSolidBrush(colorRefOf(tr.settings.BackgroundColor))
 	tr.errorBackgroundBrush = winapi.CreateSolidBrush(winapi.RGB(160, 0, 0))
 	tr.font = winapi.CreateFont(
 		15, 0, 0, 0, winapi.FW_NORMAL, 0, 0, 0,
@@ -50,7 +49,7 @@ func (tr *TipRenderer) Draw(hWnd winapi.HWND) {
 	backDc := backBuffer.begin(hWnd, hdc)
 
 	if tr.errorMessage == "" {
-		tr.drawAsTasks(hWnd, backDc, tr.tasks, tr.settings.TipTextColor)
+		tr.drawAsTasks(hWnd, backDc, tr.tasks, colorRefOf(tr.settings.TipTextColor))
 
 	} else {
 		tr.drawAsMessage(hWnd, backDc, tr.errorMessage)
@@ -122,14 +121,22 @@ func (tr *TipRenderer) createTimeText(sourceTasks []logic.Task, now time.Time) s
 			ret += "\n"
 		}
 
-		if now.Before(task.BeginAt) {
-			ret += tr.textMap.Of("INDICATOR_AFTER_MINUTES").
-				SetInt("minutes", int(math.Ceil(task.BeginAt.Sub(now).Minutes()))).
+		relative := logic.RelativeTimeOf(task, now)
+
+		switch relative.Kind {
+		case logic.RelativeUpcoming:
+			ret += tr.textMap.Of("INDICATOR_STARTS_IN").
+				Set("duration", textmap.FormatDuration(tr.textMap, relative.Duration, true)).
+				String()
+
+		case logic.RelativeOngoing:
+			ret += tr.textMap.Of("INDICATOR_REMAINING").
+				Set("duration", textmap.FormatDuration(tr.textMap, relative.Duration, true)).
 				String()
 
-		} else if now.Before(task.EndAt) {
-			ret += tr.textMap.Of("INDICATOR_REMAINING_MINUTES").
-				SetInt("minutes", int(math.Ceil(task.EndAt.Sub(now).Minutes()))).
+		case logic.RelativeEnded:
+			ret += tr.textMap.Of("INDICATOR_ENDED_AGO").
+				Set("duration", textmap.FormatDuration(tr.textMap, relative.Duration, false)).
 				String()
 		}
 	}
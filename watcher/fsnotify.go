@@ -0,0 +1,142 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type fsnotifyWatcher struct {
+	Handlers
+	debounce time.Duration
+	patterns []string
+	timers   map[string]*time.Timer
+	stopped  bool
+	pending  sync.WaitGroup
+}
+
+func New(debounce time.Duration) Watcher {
+	ret := new(fsnotifyWatcher)
+	ret.debounce = debounce
+	ret.timers = make(map[string]*time.Timer)
+	return ret
+}
+
+func (w *fsnotifyWatcher) Watch(ctx context.Context, patterns ...string) error {
+	fullpaths, err := absPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	w.patterns = fullpaths
+	errs := []error{}
+	dirs := make(map[string]bool)
+
+	for _, fullpath := range fullpaths {
+		dir := filepath.Dir(fullpath)
+		if dirs[dir] {
+			continue
+		}
+
+		dirs[dir] = true
+
+		if err := fsWatcher.Add(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	go w.run(ctx, fsWatcher, w.Start())
+
+	for _, err := range errs {
+		w.NotifyError(err)
+	}
+
+	return nil
+}
+
+func (w *fsnotifyWatcher) run(ctx context.Context, fsWatcher *fsnotify.Watcher, done chan struct{}) {
+	defer close(done)
+	defer w.pending.Wait()
+	defer w.stopTimers()
+	defer fsWatcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			if matchAny(w.patterns, event.Name) {
+				w.schedule(ctx, event.Name)
+			}
+
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+
+			w.NotifyError(err)
+		}
+	}
+}
+
+func (w *fsnotifyWatcher) schedule(ctx context.Context, filename string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if timer, ok := w.timers[filename]; ok {
+		timer.Reset(w.debounce)
+		return
+	}
+
+	w.timers[filename] = time.AfterFunc(w.debounce, func() {
+		w.fire(ctx, filename)
+	})
+}
+
+func (w *fsnotifyWatcher) fire(ctx context.Context, filename string) {
+	w.mutex.Lock()
+	delete(w.timers, filename)
+
+	if w.stopped || ctx.Err() != nil {
+		w.mutex.Unlock()
+		return
+	}
+
+	w.pending.Add(1)
+	w.mutex.Unlock()
+
+	defer w.pending.Done()
+
+	w.NotifyChanged(filename)
+}
+
+func (w *fsnotifyWatcher) stopTimers() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.stopped = true
+
+	for filename, timer := range w.timers {
+		timer.Stop()
+		delete(w.timers, filename)
+	}
+}
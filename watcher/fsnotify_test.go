@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testDebounce = time.Millisecond * 50
+
+type recorder struct {
+	mutex   sync.Mutex
+	changed []string
+	errs    []error
+}
+
+func (r *recorder) attach(w Watcher) {
+	w.OnChanged(func(filename string) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.changed = append(r.changed, filepath.Base(filename))
+	})
+
+	w.OnError(func(err error) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.errs = append(r.errs, err)
+	})
+}
+
+func (r *recorder) snapshot() ([]string, []error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string{}, r.changed...), append([]error{}, r.errs...)
+}
+
+func (r *recorder) waitChanged(t *testing.T, count int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second * 5)
+
+	for time.Now().Before(deadline) {
+		if changed, _ := r.snapshot(); count <= len(changed) {
+			time.Sleep(testDebounce * 4)
+			changed, _ = r.snapshot()
+			return changed
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	t.Fatalf("expected %d notifications", count)
+	return nil
+}
+
+func startFsnotify(t *testing.T, patterns ...string) (*recorder, context.CancelFunc, Watcher) {
+	t.Helper()
+
+	w := New(testDebounce)
+	r := new(recorder)
+	r.attach(w)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		w.Wait()
+	})
+
+	if err := w.Watch(ctx, patterns...); err != nil {
+		t.Fatal(err)
+	}
+
+	return r, cancel, w
+}
+
+func TestFsnotifyDebounceCoalescesWrites(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+	r, _, _ := startFsnotify(t, filename)
+
+	for index := 0; index < 5; index++ {
+		if err := os.WriteFile(filename, []byte{byte('a' + index)}, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if changed := r.waitChanged(t, 1); len(changed) != 1 || changed[0] != "schedule.json" {
+		t.Errorf("notified %v, expected a single schedule.json", changed)
+	}
+}
+
+func TestFsnotifyDetectsTempFileRename(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "schedule.json")
+	temporary := filepath.Join(dir, "schedule.json.tmp")
+
+	if err := os.WriteFile(filename, []byte("old"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	r, _, _ := startFsnotify(t, filename)
+
+	if err := os.WriteFile(temporary, []byte("new"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(temporary, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := r.waitChanged(t, 1)
+	for _, name := range changed {
+		if name != "schedule.json" {
+			t.Errorf("notified an unwatched file %s", name)
+		}
+	}
+}
+
+func TestFsnotifyPropagatesErrors(t *testing.T) {
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	r, _, _ := startFsnotify(t, filepath.Join(notDir, "sub", "schedule.json"))
+
+	if _, errs := r.snapshot(); len(errs) != 1 {
+		t.Errorf("reported %v, expected a single error", errs)
+	}
+}
+
+func TestFsnotifyStopsAfterCancel(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+	r, cancel, w := startFsnotify(t, filename)
+
+	if err := os.WriteFile(filename, []byte("pending"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(testDebounce / 5)
+
+	cancel()
+
+	waited := make(chan struct{})
+	go func() {
+		w.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Wait did not return after cancel")
+	}
+
+	before, _ := r.snapshot()
+
+	if err := os.WriteFile(filename, []byte("after"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(testDebounce * 4)
+
+	if after, _ := r.snapshot(); len(after) != len(before) {
+		t.Errorf("notified %v after Wait returned", after[len(before):])
+	}
+}
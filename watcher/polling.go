@@ -0,0 +1,144 @@
+package watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type pollingWatcher struct {
+	Handlers
+	interval time.Duration
+	patterns []string
+	states   map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+func NewPolling(interval time.Duration) Watcher {
+	ret := new(pollingWatcher)
+	ret.interval = interval
+	ret.states = make(map[string]fileState)
+	return ret
+}
+
+func (w *pollingWatcher) Watch(ctx context.Context, patterns ...string) error {
+	fullpaths, err := absPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	w.patterns = fullpaths
+	w.states = w.scan(nil)
+
+	go w.run(ctx, w.Start())
+
+	return nil
+}
+
+func (w *pollingWatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *pollingWatcher) poll() {
+	states := w.scan(w.states)
+
+	for filename, state := range states {
+		if previous, ok := w.states[filename]; !ok || previous.hash != state.hash {
+			w.NotifyChanged(filename)
+		}
+	}
+
+	for filename := range w.states {
+		if _, ok := states[filename]; !ok {
+			w.NotifyChanged(filename)
+		}
+	}
+
+	w.states = states
+}
+
+func (w *pollingWatcher) scan(previousStates map[string]fileState) map[string]fileState {
+	ret := make(map[string]fileState)
+
+	for _, pattern := range w.patterns {
+		filenames, err := filepath.Glob(pattern)
+		if err != nil {
+			w.NotifyError(err)
+			continue
+		}
+
+		for _, filename := range filenames {
+			fileInfo, err := os.Stat(filename)
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					w.NotifyError(err)
+				}
+				continue
+
+			} else if fileInfo.IsDir() {
+				continue
+			}
+
+			state := fileState{modTime: fileInfo.ModTime(), size: fileInfo.Size()}
+
+			if previous, ok := previousStates[filename]; ok && previous.modTime.Equal(state.modTime) && previous.size == state.size {
+				ret[filename] = previous
+				continue
+			}
+
+			if hash, err := hashOf(filename); err != nil {
+				w.NotifyError(err)
+				continue
+
+			} else {
+				state.hash = hash
+			}
+
+			ret[filename] = state
+		}
+	}
+
+	return ret
+}
+
+func hashOf(filename string) ([sha256.Size]byte, error) {
+	var ret [sha256.Size]byte
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return ret, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ret, err
+	}
+
+	copy(ret[:], hash.Sum(nil))
+
+	return ret, nil
+}
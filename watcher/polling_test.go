@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPollingDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "schedule.json")
+	added := filepath.Join(dir, "added.json")
+	baseTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	write := func(filename string, content string, modTime time.Time) {
+		t.Helper()
+
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filename, "aaaa", baseTime)
+
+	w := NewPolling(time.Hour).(*pollingWatcher)
+
+	changed := []string{}
+	w.OnChanged(func(filename string) {
+		changed = append(changed, filepath.Base(filename))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer w.Wait()
+	defer cancel()
+
+	if err := w.Watch(ctx, filepath.Join(dir, "*.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		change   func()
+		expected []string
+	}{
+		{"unchanged", func() {}, []string{}},
+		{"touched with the same content", func() { write(filename, "aaaa", baseTime.Add(time.Second)) }, []string{}},
+		{"same size and new content", func() { write(filename, "bbbb", baseTime.Add(time.Second*2)) }, []string{"schedule.json"}},
+		{"size changed", func() { write(filename, "bbbbb", baseTime.Add(time.Second*2)) }, []string{"schedule.json"}},
+		{"file added", func() { write(added, "c", baseTime) }, []string{"added.json"}},
+		{"file removed", func() { os.Remove(added) }, []string{"added.json"}},
+	}
+
+	for _, c := range cases {
+		changed = []string{}
+
+		c.change()
+		w.poll()
+
+		sort.Strings(changed)
+		if !reflect.DeepEqual(changed, c.expected) {
+			t.Errorf("%s: notified %v, expected %v", c.name, changed, c.expected)
+		}
+	}
+}
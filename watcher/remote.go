@@ -0,0 +1,7 @@
+//go:build !windows
+
+package watcher
+
+func IsRemote(pattern string) bool {
+	return false
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package watcher
+
+import (
+	"path/filepath"
+	"strings"
+	winapi2 "time-meter/winapi"
+)
+
+func IsRemote(pattern string) bool {
+	fullpath, err := filepath.Abs(pattern)
+	if err != nil {
+		return false
+	}
+
+	volume := filepath.VolumeName(fullpath)
+	if strings.HasPrefix(volume, `\\`) {
+		return true
+	}
+
+	return winapi2.GetDriveType(volume+`\`) == winapi2.DRIVE_REMOTE
+}
@@ -0,0 +1,120 @@
+package watcher
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const DEFAULT_DEBOUNCE = time.Millisecond * 200
+
+type Watcher interface {
+	Watch(ctx context.Context, patterns ...string) error
+	Wait()
+	OnChanged(handler ChangedHandler)
+	OnError(handler ErrorHandler)
+}
+
+type ChangedHandler func(filename string)
+
+type ErrorHandler func(err error)
+
+type Handlers struct {
+	mutex          sync.Mutex
+	done           chan struct{}
+	changedHandler ChangedHandler
+	errorHandler   ErrorHandler
+}
+
+func NewAuto(debounce time.Duration, interval time.Duration, patterns ...string) Watcher {
+	for _, pattern := range patterns {
+		if IsRemote(pattern) {
+			return NewPolling(interval)
+		}
+	}
+
+	return New(debounce)
+}
+
+func (h *Handlers) OnChanged(handler ChangedHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.changedHandler = handler
+}
+
+func (h *Handlers) OnError(handler ErrorHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.errorHandler = handler
+}
+
+func (h *Handlers) Wait() {
+	h.mutex.Lock()
+	done := h.done
+	h.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (h *Handlers) Start() chan struct{} {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.done != nil {
+		panic("invalid operation.")
+	}
+
+	h.done = make(chan struct{})
+
+	return h.done
+}
+
+func (h *Handlers) NotifyChanged(filename string) {
+	h.mutex.Lock()
+	handler := h.changedHandler
+	h.mutex.Unlock()
+
+	if handler != nil {
+		handler(filename)
+	}
+}
+
+func (h *Handlers) NotifyError(err error) {
+	h.mutex.Lock()
+	handler := h.errorHandler
+	h.mutex.Unlock()
+
+	if handler != nil {
+		handler(err)
+	}
+}
+
+func absPatterns(patterns []string) ([]string, error) {
+	ret := []string{}
+
+	for _, pattern := range patterns {
+		fullpath, err := filepath.Abs(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, fullpath)
+	}
+
+	return ret, nil
+}
+
+func matchAny(patterns []string, filename string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, filename); matched {
+			return true
+		}
+	}
+
+	return false
+}
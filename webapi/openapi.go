@@ -0,0 +1,49 @@
+package webapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"time-meter/jsonschema"
+	"time-meter/logic"
+)
+
+const COMPONENT_SCHEMA_PREFIX = "#/components/schemas/"
+
+//go:embed openapi.json
+var openApiJson []byte
+
+func OpenApiDocument() (map[string]any, error) {
+	var ret map[string]any
+	if err := json.Unmarshal(openApiJson, &ret); err != nil {
+		return nil, err
+	}
+
+	components, ok := ret["components"].(map[string]any)
+	if !ok {
+		return nil, errors.New("openapi.json has no components")
+	}
+
+	components["schemas"] = componentSchemas()
+
+	return ret, nil
+}
+
+func componentSchemas() map[string]jsonschema.Schema {
+	ret := jsonschema.Definitions(COMPONENT_SCHEMA_PREFIX,
+		logic.Task{},
+		logic.Interval{},
+		logic.FreeBusy{},
+		logic.TodoItem{},
+		logic.Plan{},
+		logic.Selection{},
+		logic.SelectionRequest{},
+	)
+
+	ret["Tasks"] = jsonschema.Schema{
+		"type":  "array",
+		"items": jsonschema.Schema{"$ref": COMPONENT_SCHEMA_PREFIX + "Task"},
+	}
+
+	return ret
+}
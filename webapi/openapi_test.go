@@ -0,0 +1,242 @@
+package webapi
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"time-meter/jsonschema"
+	"time-meter/logic"
+	"time-meter/store"
+)
+
+func loadOpenApiDocument(t *testing.T) map[string]any {
+	t.Helper()
+
+	document, err := OpenApiDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonBytes, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ret map[string]any
+	if err := json.Unmarshal(jsonBytes, &ret); err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
+
+func servedRoutes(t *testing.T) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "webapi.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := []string{}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		pathSwitch, ok := node.(*ast.SwitchStmt)
+		if !ok || exprString(pathSwitch.Tag) != "r.URL.Path" {
+			return true
+		}
+
+		for _, stmt := range pathSwitch.Body.List {
+			clause := stmt.(*ast.CaseClause)
+
+			for _, pathExpr := range clause.List {
+				path, _ := strconv.Unquote(pathExpr.(*ast.BasicLit).Value)
+
+				for _, inner := range clause.Body {
+					methodSwitch, ok := inner.(*ast.SwitchStmt)
+					if !ok || exprString(methodSwitch.Tag) != "r.Method" {
+						continue
+					}
+
+					for _, methodStmt := range methodSwitch.Body.List {
+						for _, methodExpr := range methodStmt.(*ast.CaseClause).List {
+							method := strings.TrimPrefix(exprString(methodExpr), "http.Method")
+							ret = append(ret, strings.ToLower(method)+" "+path)
+						}
+					}
+				}
+			}
+		}
+
+		return false
+	})
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+
+	default:
+		return ""
+	}
+}
+
+func TestOpenApiCoversEveryRoute(t *testing.T) {
+	document := loadOpenApiDocument(t)
+
+	documented := []string{}
+	for path, item := range document["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			if method != "parameters" {
+				documented = append(documented, method+" "+path)
+			}
+		}
+	}
+
+	sort.Strings(documented)
+
+	served := servedRoutes(t)
+	if len(served) == 0 {
+		t.Fatal("no routes were found in ServeHTTP")
+	}
+
+	if strings.Join(served, "\n") != strings.Join(documented, "\n") {
+		t.Errorf("served routes\n%s\ndiffer from documented routes\n%s", strings.Join(served, "\n"), strings.Join(documented, "\n"))
+	}
+}
+
+func TestOpenApiSchemaReferencesResolve(t *testing.T) {
+	document := loadOpenApiDocument(t)
+
+	var walk func(value any, path string)
+	walk = func(value any, path string) {
+		switch typed := value.(type) {
+		case map[string]any:
+			if ref, ok := typed["$ref"].(string); ok {
+				if _, ok := resolveRef(document, ref); !ok {
+					t.Errorf("%s: unresolved reference %s", path, ref)
+				}
+			}
+
+			for key, child := range typed {
+				walk(child, path+"/"+key)
+			}
+
+		case []any:
+			for index, child := range typed {
+				walk(child, path+"/"+strconv.Itoa(index))
+			}
+		}
+	}
+
+	walk(document, "#")
+}
+
+func resolveRef(document map[string]any, ref string) (any, bool) {
+	var current any = document
+
+	for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = object[strings.NewReplacer("~1", "/", "~0", "~").Replace(name)]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func TestSampleResponsesMatchOpenApi(t *testing.T) {
+	document := loadOpenApiDocument(t)
+
+	now := time.Now().Truncate(time.Hour)
+	from := now.Format(time.RFC3339)
+	to := now.Add(time.Hour * 24 * 7).Format(time.RFC3339)
+
+	wa := New()
+	wa.SetStore(store.NewMemory([]logic.Task{
+		{ID: "a", Subject: "A", BeginAt: now.Add(time.Hour), EndAt: now.Add(time.Hour * 2)},
+		{Subject: "B", BeginAt: now.Add(time.Hour * 3), EndAt: now.Add(time.Hour * 4)},
+	}))
+	wa.SetThemes(logic.Selection{Active: "dark", Available: []string{"dark", "light"}})
+	wa.SetProfiles(logic.Selection{Available: []string{"focus"}})
+	wa.SetFreeBusyOption(logic.FreeBusyOption{WorkdayBeginAt: 0, WorkdayEndAt: time.Hour * 24, MinimumSlot: time.Minute * 15})
+
+	window := "from=" + from + "&to=" + to
+
+	cases := []struct {
+		method string
+		path   string
+		query  string
+		body   string
+	}{
+		{http.MethodGet, "/schedule", "", ""},
+		{http.MethodGet, "/schedule", window, ""},
+		{http.MethodGet, "/schedule", "from=yesterday", ""},
+		{http.MethodPost, "/schedule", "mode=append", `[{"subject": "C", "begin_at": "` + to + `", "end_at": "` + to + `"}]`},
+		{http.MethodGet, "/freebusy", window, ""},
+		{http.MethodGet, "/slots", "duration=30m&" + window, ""},
+		{http.MethodGet, "/slots", "duration=soon", ""},
+		{http.MethodPost, "/plan", window, `[{"subject": "D", "estimate": "30m", "priority": 1}, {"subject": "E", "estimate": "0s"}]`},
+		{http.MethodPost, "/shift", "offset=15m&from=" + from, ""},
+		{http.MethodGet, "/theme", "", ""},
+		{http.MethodPost, "/theme", "", `{"name": "light"}`},
+		{http.MethodGet, "/profile", "", ""},
+		{http.MethodPost, "/profile", "", `{"name": "missing"}`},
+		{http.MethodGet, "/schema/schedule.json", "", ""},
+		{http.MethodGet, "/schema/settings.json", "", ""},
+		{http.MethodGet, "/openapi.json", "", ""},
+	}
+
+	for _, c := range cases {
+		name := c.method + " " + c.path + "?" + c.query
+		recorder := serve(wa, c.method, c.path+"?"+strings.ReplaceAll(c.query, "+", "%2B"), "", c.body)
+
+		status := strconv.Itoa(recorder.Code)
+		operation := document["paths"].(map[string]any)[c.path].(map[string]any)[strings.ToLower(c.method)].(map[string]any)
+
+		response, ok := operation["responses"].(map[string]any)[status].(map[string]any)
+		if !ok {
+			t.Errorf("%s: status %s is not documented: %s", name, status, recorder.Body)
+			continue
+		}
+
+		content, _ := response["content"].(map[string]any)
+		media, ok := content["application/json"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		schema := jsonschema.Schema{}
+		for key, value := range document {
+			schema[key] = value
+		}
+		schema["$ref"] = "#/paths/" + strings.ReplaceAll(c.path, "/", "~1") + "/" + strings.ToLower(c.method) + "/responses/" + status + "/content/application~1json/schema"
+
+		if media["schema"] == nil {
+			t.Errorf("%s: response %s has no schema", name, status)
+
+		} else if err := jsonschema.Validate(schema, recorder.Body.Bytes()); err != nil {
+			t.Errorf("%s: %v\n%s", name, err, recorder.Body)
+		}
+	}
+}
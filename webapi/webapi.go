@@ -1,15 +1,32 @@
 package webapi
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"time-meter/logic"
+	"time-meter/setting"
+	"time-meter/store"
 )
 
+const DEFAULT_PLAN_DAYS = 7
+
 type WebApi interface {
 	http.Handler
 
-	PostedTasks() []logic.Task
+	SetStore(taskStore store.TaskStore)
+	SetThemes(selection logic.Selection)
+	SetProfiles(selection logic.Selection)
+	SetFreeBusyOption(option logic.FreeBusyOption)
+	PostedTheme() string
+	PostedProfile() string
 	OnHandled(handler HandledHandler)
 }
 
@@ -17,12 +34,20 @@ type RequestType int
 
 const (
 	PostSchedule RequestType = iota + 1
+	PostTheme
+	PostProfile
 )
 
 type HandledHandler func(t RequestType)
 
 type webApi struct {
-	postedTasks    []logic.Task
+	mutex          sync.Mutex
+	taskStore      store.TaskStore
+	themes         logic.Selection
+	profiles       logic.Selection
+	freeBusyOption logic.FreeBusyOption
+	postedTheme    string
+	postedProfile  string
 	handledHandler HandledHandler
 }
 
@@ -31,10 +56,46 @@ func New() WebApi {
 	return ret
 }
 
-func (wa *webApi) PostedTasks() []logic.Task {
-	ret := []logic.Task{}
-	ret = append(ret, wa.postedTasks...)
-	return ret
+func (wa *webApi) SetStore(taskStore store.TaskStore) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	wa.taskStore = taskStore
+}
+
+func (wa *webApi) SetThemes(selection logic.Selection) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	wa.themes = selection
+}
+
+func (wa *webApi) SetProfiles(selection logic.Selection) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	wa.profiles = selection
+}
+
+func (wa *webApi) SetFreeBusyOption(option logic.FreeBusyOption) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	wa.freeBusyOption = option
+}
+
+func (wa *webApi) PostedTheme() string {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	return wa.postedTheme
+}
+
+func (wa *webApi) PostedProfile() string {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	return wa.postedProfile
 }
 
 func (wa *webApi) OnHandled(handler HandledHandler) {
@@ -42,13 +103,95 @@ func (wa *webApi) OnHandled(handler HandledHandler) {
 }
 
 func (wa *webApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var handled RequestType
 	var err error
 
 	switch r.URL.Path {
 	case "/schedule":
 		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetSchedule(w, r)
+
 		case http.MethodPost:
-			err = wa.handlePostSchedule(w, r)
+			handled, err = wa.handlePostSchedule(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/freebusy":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetFreeBusy(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/slots":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetSlots(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/plan":
+		switch r.Method {
+		case http.MethodPost:
+			handled, err = wa.handlePostPlan(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/shift":
+		switch r.Method {
+		case http.MethodPost:
+			handled, err = wa.handlePostShift(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/theme":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetSelection(w, r, &wa.themes)
+
+		case http.MethodPost:
+			handled, err = wa.handlePostSelection(w, r, &wa.themes, &wa.postedTheme, PostTheme)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/profile":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetSelection(w, r, &wa.profiles)
+
+		case http.MethodPost:
+			handled, err = wa.handlePostSelection(w, r, &wa.profiles, &wa.postedProfile, PostProfile)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/schema/schedule.json", "/schema/settings.json":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetSchema(w, r)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+
+	case "/openapi.json":
+		switch r.Method {
+		case http.MethodGet:
+			err = wa.handleGetOpenApi(w, r)
 
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -63,20 +206,596 @@ func (wa *webApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		println(err.Error())
 		return
 	}
+
+	if handled != 0 && wa.handledHandler != nil {
+		wa.handledHandler(handled)
+	}
 }
 
-func (wa *webApi) handlePostSchedule(w http.ResponseWriter, r *http.Request) error {
-	if err := json.NewDecoder(r.Body).Decode(&wa.postedTasks); err != nil {
+func (wa *webApi) handleGetSchedule(w http.ResponseWriter, r *http.Request) error {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return nil
+	}
+
+	query := r.URL.Query()
+	if query.Get("from") == "" && query.Get("to") == "" {
+		tasks, etag, err := wa.loadStoredTasks()
+		if err != nil {
+			return err
+		}
+
+		return writeTasks(w, http.StatusOK, tasks, etag)
+	}
+
+	if beginAt, err := time.Parse(time.RFC3339, query.Get("from")); err != nil {
+		http.Error(w, fmt.Sprintf(`invalid "from": %s`, err.Error()), http.StatusBadRequest)
+		return nil
+
+	} else if endAt, err := time.Parse(time.RFC3339, query.Get("to")); err != nil {
+		http.Error(w, fmt.Sprintf(`invalid "to": %s`, err.Error()), http.StatusBadRequest)
+		return nil
+
+	} else if tasks, err := wa.taskStore.Between(beginAt, endAt); err != nil && !isLoadError(err) {
 		return err
+
+	} else {
+		return writeTasks(w, http.StatusOK, tasks, "")
+	}
+}
+
+func (wa *webApi) handlePostSchedule(w http.ResponseWriter, r *http.Request) (RequestType, error) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return 0, nil
+	}
+
+	currentTasks, err := wa.taskStore.Load()
+	if isLoadError(err) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return 0, nil
+
+	} else if err != nil {
+		return 0, err
+	}
+
+	if ok, err := wa.checkIfMatch(w, r, currentTasks); !ok {
+		return 0, err
+	}
+
+	option, err := parseMergeOption(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	var postedTasks []logic.Task
+	if err := json.NewDecoder(r.Body).Decode(&postedTasks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	mergedTasks, err := logic.MergeTasks(currentTasks, postedTasks, option)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	if err := wa.taskStore.Save(mergedTasks); err != nil {
+		return 0, err
+	}
+
+	if _, etag, err := wa.loadStoredTasks(); err != nil {
+		return 0, err
+
+	} else {
+		w.Header().Set("ETag", etag)
 	}
 
 	if _, err := w.Write([]byte("ok")); err != nil {
+		return 0, err
+	}
+
+	return PostSchedule, nil
+}
+
+func (wa *webApi) handleGetFreeBusy(w http.ResponseWriter, r *http.Request) error {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return nil
+	}
+
+	beginAt, endAt, err := parseRange(r, startOfDay(time.Now()), 1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	tasks, err := wa.loadTasks()
+	if err != nil {
+		return err
+	}
+
+	return writeJson(w, http.StatusOK, logic.ComputeFreeBusy(tasks, beginAt, endAt, wa.freeBusyOption))
+}
+
+func (wa *webApi) handleGetSlots(w http.ResponseWriter, r *http.Request) error {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return nil
+	}
+
+	duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil || duration <= 0 {
+		http.Error(w, `invalid "duration", expected such as "30m"`, http.StatusBadRequest)
+		return nil
+	}
+
+	beginAt, endAt, err := parseRange(r, time.Now(), 1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	tasks, err := wa.loadTasks()
+	if err != nil {
 		return err
 	}
 
-	if wa.handledHandler != nil {
-		wa.handledHandler(PostSchedule)
+	return writeJson(w, http.StatusOK, logic.FindSlots(tasks, beginAt, endAt, duration, wa.freeBusyOption))
+}
+
+func (wa *webApi) handlePostPlan(w http.ResponseWriter, r *http.Request) (RequestType, error) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return 0, nil
+	}
+
+	beginAt, endAt, err := parseRange(r, time.Now(), DEFAULT_PLAN_DAYS)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	apply := false
+	if value := r.URL.Query().Get("apply"); value != "" {
+		if apply, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, `invalid "apply", expected "true" or "false"`, http.StatusBadRequest)
+			return 0, nil
+		}
+	}
+
+	var items []logic.TodoItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	tasks, err := wa.taskStore.Load()
+	if apply && isLoadError(err) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return 0, nil
+
+	} else if err != nil && !isLoadError(err) {
+		return 0, err
+	}
+
+	if apply {
+		if ok, err := wa.checkIfMatch(w, r, tasks); !ok {
+			return 0, err
+		}
+	}
+
+	plan := logic.PlanTodoItems(tasks, items, beginAt, endAt, wa.freeBusyOption)
+
+	if !apply || len(plan.Planned) == 0 {
+		return 0, writeJson(w, http.StatusOK, plan)
+	}
+
+	if err := wa.taskStore.Save(append(tasks, plan.Planned...)); err != nil {
+		return 0, err
+	}
+
+	if _, etag, err := wa.loadStoredTasks(); err != nil {
+		return 0, err
+
+	} else {
+		w.Header().Set("ETag", etag)
+	}
+
+	return PostSchedule, writeJson(w, http.StatusOK, plan)
+}
+
+func (wa *webApi) handlePostShift(w http.ResponseWriter, r *http.Request) (RequestType, error) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	if wa.taskStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return 0, nil
+	}
+
+	option, err := parseShiftOption(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	currentTasks, err := wa.taskStore.Load()
+	if isLoadError(err) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return 0, nil
+
+	} else if err != nil {
+		return 0, err
+	}
+
+	if ok, err := wa.checkIfMatch(w, r, currentTasks); !ok {
+		return 0, err
+	}
+
+	shiftedTasks, count, err := logic.ShiftTasks(currentTasks, option)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	if count == 0 {
+		if etag, err := computeETag(currentTasks, nil); err != nil {
+			return 0, err
+
+		} else {
+			return 0, writeTasks(w, http.StatusOK, currentTasks, etag)
+		}
+	}
+
+	if err := wa.taskStore.Save(shiftedTasks); err != nil {
+		return 0, err
+	}
+
+	if storedTasks, etag, err := wa.loadStoredTasks(); err != nil {
+		return 0, err
+
+	} else {
+		return PostSchedule, writeTasks(w, http.StatusOK, storedTasks, etag)
+	}
+}
+
+func (wa *webApi) handleGetSelection(w http.ResponseWriter, r *http.Request, selection *logic.Selection) error {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	return writeSelection(w, http.StatusOK, *selection)
+}
+
+func (wa *webApi) handlePostSelection(w http.ResponseWriter, r *http.Request, selection *logic.Selection, posted *string, requestType RequestType) (RequestType, error) {
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+
+	var body logic.SelectionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if etag, err := computeSelectionETag(*selection); err != nil {
+			return 0, err
+
+		} else if !matchETag(ifMatch, etag) {
+			return 0, writeSelection(w, http.StatusPreconditionFailed, *selection)
+		}
+	}
+
+	found := body.Name == ""
+	for _, name := range selection.Available {
+		found = found || name == body.Name
+	}
+
+	if !found {
+		http.Error(w, fmt.Sprintf(`unknown name "%s"`, body.Name), http.StatusBadRequest)
+		return 0, nil
+	}
+
+	*posted = body.Name
+	selection.Active = body.Name
+
+	if etag, err := computeSelectionETag(*selection); err != nil {
+		return 0, err
+
+	} else {
+		w.Header().Set("ETag", etag)
+	}
+
+	if _, err := w.Write([]byte("ok")); err != nil {
+		return 0, err
+	}
+
+	return requestType, nil
+}
+
+func (wa *webApi) handleGetSchema(w http.ResponseWriter, r *http.Request) error {
+	switch r.URL.Path {
+	case "/schema/schedule.json":
+		return writeJson(w, http.StatusOK, logic.ScheduleJSONSchema())
+
+	default:
+		return writeJson(w, http.StatusOK, setting.JSONSchema())
+	}
+}
+
+func (wa *webApi) handleGetOpenApi(w http.ResponseWriter, r *http.Request) error {
+	if document, err := OpenApiDocument(); err != nil {
+		return err
+
+	} else {
+		return writeJson(w, http.StatusOK, document)
+	}
+}
+
+func (wa *webApi) loadTasks() ([]logic.Task, error) {
+	tasks, err := wa.taskStore.Load()
+	if err != nil && !isLoadError(err) {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func isLoadError(err error) bool {
+	var loadErr *store.LoadError
+	return errors.As(err, &loadErr)
+}
+
+func (wa *webApi) checkIfMatch(w http.ResponseWriter, r *http.Request, currentTasks []logic.Task) (bool, error) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true, nil
+	}
+
+	if etag, err := computeETag(currentTasks, nil); err != nil {
+		return false, err
+
+	} else if !matchETag(ifMatch, etag) {
+		return false, writeTasks(w, http.StatusPreconditionFailed, currentTasks, etag)
+	}
+
+	return true, nil
+}
+
+func (wa *webApi) loadStoredTasks() ([]logic.Task, string, error) {
+	tasks, loadErr := wa.taskStore.Load()
+	if loadErr != nil && !isLoadError(loadErr) {
+		return nil, "", loadErr
+	}
+
+	etag, err := computeETag(tasks, loadErr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tasks, etag, nil
+}
+
+func writeTasks(w http.ResponseWriter, statusCode int, tasks []logic.Task, etag string) error {
+	jsonBytes, err := encodeTasks(tasks)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(jsonBytes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func writeSelection(w http.ResponseWriter, statusCode int, selection logic.Selection) error {
+	if etag, err := computeSelectionETag(selection); err != nil {
+		return err
+
+	} else {
+		w.Header().Set("ETag", etag)
+	}
+
+	return writeJson(w, statusCode, selection)
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, value any) error {
+	jsonBuffer := bytes.NewBuffer(nil)
+
+	encoder := json.NewEncoder(jsonBuffer)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(jsonBuffer.Bytes()); err != nil {
+		return err
 	}
 
 	return nil
 }
+
+func parseMergeOption(r *http.Request) (logic.MergeOption, error) {
+	var ret logic.MergeOption
+	query := r.URL.Query()
+
+	if mode, err := logic.ParseMergeMode(query.Get("mode"), query.Get("key")); err != nil {
+		return ret, err
+
+	} else {
+		ret.Mode = mode
+	}
+
+	if ret.Mode != logic.MergeReplaceRange {
+		return ret, nil
+	}
+
+	if beginAt, err := time.Parse(time.RFC3339, query.Get("from")); err != nil {
+		return ret, fmt.Errorf(`invalid "from": %w`, err)
+
+	} else if endAt, err := time.Parse(time.RFC3339, query.Get("to")); err != nil {
+		return ret, fmt.Errorf(`invalid "to": %w`, err)
+
+	} else {
+		ret.RangeBeginAt = beginAt
+		ret.RangeEndAt = endAt
+	}
+
+	return ret, nil
+}
+
+func parseShiftOption(r *http.Request) (logic.ShiftOption, error) {
+	var ret logic.ShiftOption
+	query := r.URL.Query()
+
+	if mode, err := logic.ParseShiftMode(query.Get("mode")); err != nil {
+		return ret, err
+
+	} else if offset, err := time.ParseDuration(query.Get("offset")); err != nil {
+		return ret, errors.New(`invalid "offset", expected such as "10m" or "-5m"`)
+
+	} else {
+		ret.Mode = mode
+		ret.Offset = offset
+	}
+
+	ret.From = time.Now()
+
+	if from := query.Get("from"); from != "" {
+		if parsed, err := time.Parse(time.RFC3339, from); err != nil {
+			return ret, fmt.Errorf(`invalid "from": %w`, err)
+
+		} else {
+			ret.From = parsed
+		}
+	}
+
+	if compress := query.Get("compress"); compress != "" {
+		if parsed, err := strconv.ParseBool(compress); err != nil {
+			return ret, errors.New(`invalid "compress", expected "true" or "false"`)
+
+		} else {
+			ret.Compress = parsed
+		}
+	}
+
+	return ret, nil
+}
+
+func parseRange(r *http.Request, defaultBeginAt time.Time, defaultDays int) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	beginAt := defaultBeginAt
+
+	if from := query.Get("from"); from != "" {
+		if parsed, err := time.Parse(time.RFC3339, from); err != nil {
+			return beginAt, beginAt, fmt.Errorf(`invalid "from": %w`, err)
+
+		} else {
+			beginAt = parsed
+		}
+	}
+
+	endAt := startOfDay(beginAt).AddDate(0, 0, defaultDays)
+
+	if to := query.Get("to"); to != "" {
+		if parsed, err := time.Parse(time.RFC3339, to); err != nil {
+			return beginAt, endAt, fmt.Errorf(`invalid "to": %w`, err)
+
+		} else {
+			endAt = parsed
+		}
+	}
+
+	if !beginAt.Before(endAt) {
+		return beginAt, endAt, fmt.Errorf(`"from" must be earlier than "to"`)
+	}
+
+	return beginAt, endAt, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func encodeTasks(tasks []logic.Task) ([]byte, error) {
+	if tasks == nil {
+		tasks = []logic.Task{}
+	}
+
+	jsonBuffer := bytes.NewBuffer(nil)
+
+	encoder := json.NewEncoder(jsonBuffer)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(tasks); err != nil {
+		return nil, err
+	}
+
+	return jsonBuffer.Bytes(), nil
+}
+
+func computeETag(tasks []logic.Task, loadErr error) (string, error) {
+	jsonBytes, err := encodeTasks(tasks)
+	if err != nil {
+		return "", err
+	}
+
+	if loadErr != nil {
+		return etagOf(append(jsonBytes, loadErr.Error()...)), nil
+	}
+
+	return etagOf(jsonBytes), nil
+}
+
+func etagOf(jsonBytes []byte) string {
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(jsonBytes))
+}
+
+func computeSelectionETag(selection logic.Selection) (string, error) {
+	jsonBytes, err := json.Marshal(selection)
+	if err != nil {
+		return "", err
+	}
+
+	return etagOf(jsonBytes), nil
+}
+
+func matchETag(ifMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
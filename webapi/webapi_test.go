@@ -0,0 +1,267 @@
+package webapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"time-meter/logic"
+	"time-meter/store"
+)
+
+func TestPostSelectionCallsHandlerWithoutLock(t *testing.T) {
+	wa := New()
+	wa.SetThemes(logic.Selection{Available: []string{"dark"}})
+
+	wa.OnHandled(func(requestType RequestType) {
+		if requestType != PostTheme {
+			t.Errorf("unexpected request type %d", requestType)
+		}
+
+		wa.SetThemes(logic.Selection{Active: wa.PostedTheme(), Available: []string{"dark"}})
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		request := httptest.NewRequest(http.MethodPost, "/theme", strings.NewReader(`{"name":"dark"}`))
+		recorder := httptest.NewRecorder()
+		wa.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("unexpected status %d", recorder.Code)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("POST /theme did not return")
+	}
+}
+
+func TestMutationsRejectedWhileScheduleFailsToLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "schedule.json")
+	brokenJson := []byte(`{"version": 2, "tasks": [`)
+
+	if err := os.WriteFile(filename, brokenJson, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	wa := New()
+	wa.SetStore(store.NewJsonFile([]string{filename}, nil))
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/schedule?mode=append", strings.NewReader(`[]`)),
+		httptest.NewRequest(http.MethodPost, "/plan?apply=true", strings.NewReader(`[{"subject":"a","estimate":"30m"}]`)),
+		httptest.NewRequest(http.MethodPost, "/shift?offset=10m&from=2000-01-01T00:00:00Z", nil),
+	}
+
+	for _, request := range requests {
+		recorder := httptest.NewRecorder()
+		wa.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusConflict {
+			t.Errorf("%s %s: unexpected status %d", request.Method, request.URL, recorder.Code)
+		}
+	}
+
+	if jsonBytes, err := os.ReadFile(filename); err != nil {
+		t.Fatal(err)
+
+	} else if string(jsonBytes) != string(brokenJson) {
+		t.Errorf("the schedule was overwritten: %s", jsonBytes)
+	}
+}
+
+func TestMutationsCreateMissingSchedule(t *testing.T) {
+	beginAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	requests := []struct {
+		method  string
+		target  string
+		body    string
+		creates bool
+	}{
+		{http.MethodPost, "/schedule?mode=append", `[{"subject": "A", "begin_at": "2030-01-01T09:00:00Z", "end_at": "2030-01-01T10:00:00Z"}]`, true},
+		{http.MethodPost, "/plan?apply=true&from=2030-01-01T00:00:00Z", `[{"subject": "B", "estimate": "30m"}]`, true},
+		{http.MethodPost, "/shift?offset=10m&from=2000-01-01T00:00:00Z", "", false},
+	}
+
+	for _, r := range requests {
+		filename := filepath.Join(t.TempDir(), "schedule.json")
+
+		wa := New()
+		wa.SetStore(store.NewJsonFile([]string{filename}, nil))
+		wa.SetFreeBusyOption(logic.FreeBusyOption{WorkdayBeginAt: 0, WorkdayEndAt: time.Hour * 24})
+
+		if recorder := serve(wa, r.method, r.target, "", r.body); recorder.Code != http.StatusOK {
+			t.Errorf("%s %s: unexpected status %d: %s", r.method, r.target, recorder.Code, recorder.Body)
+			continue
+		}
+
+		if !r.creates {
+			continue
+		}
+
+		if tasks, err := logic.LoadTasksFromFile(filename); err != nil {
+			t.Errorf("%s %s: %v", r.method, r.target, err)
+
+		} else if len(tasks) != 1 || tasks[0].BeginAt.Before(beginAt) {
+			t.Errorf("%s %s: saved %v", r.method, r.target, tasks)
+		}
+	}
+}
+
+func serve(wa WebApi, method string, target string, ifMatch string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if ifMatch != "" {
+		request.Header.Set("If-Match", ifMatch)
+	}
+
+	recorder := httptest.NewRecorder()
+	wa.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestMutationsHonorIfMatch(t *testing.T) {
+	beginAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	wa := New()
+	wa.SetStore(store.NewMemory([]logic.Task{{ID: "a", Subject: "A", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}}))
+	wa.SetThemes(logic.Selection{Available: []string{"dark"}})
+
+	etag := serve(wa, http.MethodGet, "/schedule", "", "").Header().Get("ETag")
+	themeEtag := serve(wa, http.MethodGet, "/theme", "", "").Header().Get("ETag")
+
+	cases := []struct {
+		method  string
+		target  string
+		ifMatch string
+		body    string
+		status  int
+	}{
+		{http.MethodPost, "/shift?offset=10m&from=2030-01-01T00:00:00Z", `"stale"`, "", http.StatusPreconditionFailed},
+		{http.MethodPost, "/shift?offset=10m&from=2030-01-01T00:00:00Z", "W/" + etag, "", http.StatusPreconditionFailed},
+		{http.MethodPost, "/plan?apply=true&from=2030-01-01T00:00:00Z", `"stale"`, `[{"subject":"b","estimate":"30m"}]`, http.StatusPreconditionFailed},
+		{http.MethodPost, "/plan?from=2030-01-01T00:00:00Z", `"stale"`, `[{"subject":"b","estimate":"30m"}]`, http.StatusOK},
+		{http.MethodPost, "/theme", `"stale"`, `{"name":"dark"}`, http.StatusPreconditionFailed},
+		{http.MethodPost, "/theme", themeEtag, `{"name":"dark"}`, http.StatusOK},
+		{http.MethodPost, "/shift?offset=10m&from=2030-01-01T00:00:00Z", etag, "", http.StatusOK},
+		{http.MethodPost, "/schedule?mode=append", etag, `[]`, http.StatusPreconditionFailed},
+	}
+
+	for _, c := range cases {
+		recorder := serve(wa, c.method, c.target, c.ifMatch, c.body)
+
+		if recorder.Code != c.status {
+			t.Errorf("%s %s with %s: unexpected status %d", c.method, c.target, c.ifMatch, recorder.Code)
+		}
+
+		if recorder.Code == http.StatusPreconditionFailed && recorder.Header().Get("ETag") == "" {
+			t.Errorf("%s %s: 412 without the current ETag", c.method, c.target)
+		}
+	}
+}
+
+func TestPartialScheduleHasDistinctETag(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	broken := filepath.Join(dir, "broken.json")
+
+	if err := logic.SaveScheduleToFile(good, logic.NewSchedule(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(broken, []byte(`{`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	wa := New()
+	wa.SetStore(store.NewJsonFile([]string{good, broken}, nil))
+
+	recorder := serve(wa, http.MethodGet, "/schedule", "", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+
+	if cleanETag, _ := computeETag(nil, nil); recorder.Header().Get("ETag") == cleanETag {
+		t.Error("the partial schedule has the ETag of the loadable tasks")
+	}
+}
+
+func TestMutationETagMatchesStoredVersion(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	defer func() { time.Local = local }()
+
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "a.json")
+	project := filepath.Join(dir, "b.json")
+	beginAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := logic.SaveScheduleToFile(project, logic.NewSchedule([]logic.Task{{ID: "b", Subject: "B", BeginAt: beginAt, EndAt: beginAt.Add(time.Hour)}})); err != nil {
+		t.Fatal(err)
+	}
+
+	sqliteStore, err := store.NewSqlite(filepath.Join(dir, "tasks.db"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqliteStore.Close()
+
+	stores := map[string]store.TaskStore{
+		"json":   store.NewJsonFile([]string{primary, project}, nil),
+		"sqlite": sqliteStore,
+	}
+
+	requests := []struct {
+		target string
+		body   string
+	}{
+		{"/schedule?mode=append", `[{"id": "a", "subject": "A", "begin_at": "2030-01-01T11:00:00Z", "end_at": "2030-01-01T12:00:00Z"}]`},
+		{"/shift?offset=10m&from=2030-01-01T10:00:00Z", ""},
+		{"/plan?apply=true&from=2030-01-02T00:00:00Z&to=2030-01-03T00:00:00Z", `[{"subject": "C", "estimate": "30m"}]`},
+	}
+
+	for name, taskStore := range stores {
+		wa := New()
+		wa.SetStore(taskStore)
+		wa.SetFreeBusyOption(logic.FreeBusyOption{WorkdayBeginAt: 0, WorkdayEndAt: time.Hour * 24})
+
+		etag := serve(wa, http.MethodGet, "/schedule", "", "").Header().Get("ETag")
+
+		for _, r := range requests {
+			recorder := serve(wa, http.MethodPost, r.target, etag, r.body)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("%s: POST %s: unexpected status %d: %s", name, r.target, recorder.Code, recorder.Body)
+			}
+
+			etag = recorder.Header().Get("ETag")
+
+			if stored := serve(wa, http.MethodGet, "/schedule", "", "").Header().Get("ETag"); etag != stored {
+				t.Errorf("%s: POST %s returned ETag %s, but GET returns %s", name, r.target, etag, stored)
+			}
+		}
+	}
+}
+
+func TestRangeScheduleHasNoETag(t *testing.T) {
+	wa := New()
+	wa.SetStore(store.NewMemory(nil))
+
+	recorder := serve(wa, http.MethodGet, "/schedule?from=2030-01-01T00:00:00Z&to=2030-01-02T00:00:00Z", "", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+
+	if etag := recorder.Header().Get("ETag"); etag != "" {
+		t.Errorf("a range returned the ETag %s", etag)
+	}
+}
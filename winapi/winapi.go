@@ -8,33 +8,71 @@ import (
 )
 
 const (
-	LWA_COLORKEY = 0x00000001
-	LWA_ALPHA    = 0x00000002
-	MB_TOPMOST   = 0x00040000
+	AC_SRC_OVER            = 0x00
+	DRIVE_REMOTE           = 4
+	LOCALE_NAME_MAX_LENGTH = 85
+	LWA_COLORKEY           = 0x00000001
+	LWA_ALPHA              = 0x00000002
+	MB_TOPMOST             = 0x00040000
 )
 
 var (
 	// Library
-	libgdi32  uintptr
-	libuser32 uintptr
+	libgdi32    uintptr
+	libkernel32 uintptr
+	libmsimg32  uintptr
+	libuser32   uintptr
 
 	// Functions
+	alphaBlend                 uintptr
 	createCompatibleBitmap     uintptr
 	enumDisplayMonitors        uintptr
+	getDriveType               uintptr
+	getUserDefaultLocaleName   uintptr
 	setLayeredWindowAttributes uintptr
 )
 
 func init() {
 	// Library
 	libgdi32 = winapi.MustLoadLibrary("gdi32.dll")
+	libkernel32 = winapi.MustLoadLibrary("kernel32.dll")
+	libmsimg32 = winapi.MustLoadLibrary("msimg32.dll")
 	libuser32 = winapi.MustLoadLibrary("user32.dll")
 
 	// Functions
+	alphaBlend = winapi.MustGetProcAddress(libmsimg32, "AlphaBlend")
 	createCompatibleBitmap = winapi.MustGetProcAddress(libgdi32, "CreateCompatibleBitmap")
 	enumDisplayMonitors = winapi.MustGetProcAddress(libuser32, "EnumDisplayMonitors")
+	getDriveType = winapi.MustGetProcAddress(libkernel32, "GetDriveTypeW")
+	getUserDefaultLocaleName = winapi.MustGetProcAddress(libkernel32, "GetUserDefaultLocaleName")
 	setLayeredWindowAttributes = winapi.MustGetProcAddress(libuser32, "SetLayeredWindowAttributes")
 }
 
+type BLENDFUNCTION struct {
+	BlendOp             byte
+	BlendFlags          byte
+	SourceConstantAlpha byte
+	AlphaFormat         byte
+}
+
+func AlphaBlend(hdcDest winapi.HDC, xoriginDest, yoriginDest, wDest, hDest int32, hdcSrc winapi.HDC, xoriginSrc, yoriginSrc, wSrc, hSrc int32, ftn BLENDFUNCTION) bool {
+	ret, _, _ := syscall.SyscallN(alphaBlend,
+		uintptr(hdcDest),
+		uintptr(xoriginDest),
+		uintptr(yoriginDest),
+		uintptr(wDest),
+		uintptr(hDest),
+		uintptr(hdcSrc),
+		uintptr(xoriginSrc),
+		uintptr(yoriginSrc),
+		uintptr(wSrc),
+		uintptr(hSrc),
+		uintptr(*(*uint32)(unsafe.Pointer(&ftn))),
+	)
+
+	return ret != 0
+}
+
 func CreateCompatibleBitmap(hdc winapi.HDC, cx, cy int32) winapi.HBITMAP {
 	ret, _, _ := syscall.SyscallN(createCompatibleBitmap,
 		uintptr(hdc),
@@ -56,6 +94,31 @@ func EnumDisplayMonitors(hdc winapi.HDC, lprcClip *winapi.RECT, lpfnEnum uintptr
 	return ret != 0
 }
 
+func GetDriveType(rootPathName string) uint32 {
+	rootPathNamePtr, _ := syscall.UTF16PtrFromString(rootPathName)
+
+	ret, _, _ := syscall.SyscallN(getDriveType,
+		uintptr(unsafe.Pointer(rootPathNamePtr)),
+	)
+
+	return uint32(ret)
+}
+
+func GetUserDefaultLocaleName() string {
+	buffer := make([]uint16, LOCALE_NAME_MAX_LENGTH)
+
+	ret, _, _ := syscall.SyscallN(getUserDefaultLocaleName,
+		uintptr(unsafe.Pointer(&buffer[0])),
+		uintptr(len(buffer)),
+	)
+
+	if ret == 0 {
+		return ""
+	}
+
+	return syscall.UTF16ToString(buffer)
+}
+
 func SetLayeredWindowAttributes(hwnd winapi.HWND, crKey winapi.COLORREF, bAlpha byte, dwFlags winapi.DWORD) bool {
 	ret, _, _ := syscall.SyscallN(setLayeredWindowAttributes,
 		uintptr(hwnd),